@@ -0,0 +1,113 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AcceptEntry - a single value from an Accept style header (e.g.: Accept,
+// Accept-Language or Accept-Encoding)
+type AcceptEntry struct {
+	// Value - the (lower case) value without any parameters (e.g.: 'text/html')
+	Value string
+
+	// Quality - the relative preference for the value (the 'q' parameter)
+	Quality float64
+
+	// Params - any other parameters (e.g.: 'level=1')
+	Params map[string]string
+}
+
+// ParseAccept - parses an Accept style header and returns the entries ordered by
+// preference (highest quality first, then the most specific, then the order they
+// appeared in the header). Entries with a quality of zero are included so that
+// values can be explicitly excluded
+func ParseAccept(header string) []AcceptEntry {
+	var entries []AcceptEntry
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		value := strings.ToLower(strings.TrimSpace(params[0]))
+		if value == "" {
+			continue
+		}
+		entry := AcceptEntry{
+			Value:   value,
+			Quality: 1,
+		}
+		for _, param := range params[1:] {
+			keyValue := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(keyValue) != 2 {
+				continue
+			}
+			key := strings.ToLower(strings.TrimSpace(keyValue[0]))
+			paramValue := strings.Trim(strings.TrimSpace(keyValue[1]), "\"")
+			if key == "q" {
+				if quality, parseErr := strconv.ParseFloat(paramValue, 64); parseErr == nil &&
+					quality >= 0 && quality <= 1 {
+					entry.Quality = quality
+				}
+				continue
+			}
+			if entry.Params == nil {
+				entry.Params = map[string]string{}
+			}
+			entry.Params[key] = paramValue
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Quality != entries[j].Quality {
+			return entries[i].Quality > entries[j].Quality
+		}
+		return acceptSpecificity(entries[i].Value) > acceptSpecificity(entries[j].Value)
+	})
+	return entries
+}
+
+// Matches - returns true if the entry value matches the value. Wildcards are
+// supported for the whole value ('*') and for media subtypes ('text/*')
+func (ae AcceptEntry) Matches(value string) bool {
+	value = strings.ToLower(value)
+	if ae.Value == "*" || ae.Value == "*/*" || ae.Value == value {
+		return true
+	}
+	if strings.HasSuffix(ae.Value, "/*") {
+		return strings.HasPrefix(value, strings.TrimSuffix(ae.Value, "*"))
+	}
+	return false
+}
+
+// acceptSpecificity - ranks how specific a value is (wildcards are least specific)
+func acceptSpecificity(value string) int {
+	switch {
+	case value == "*" || value == "*/*":
+		return 0
+	case strings.HasSuffix(value, "/*"):
+		return 1
+	}
+	return 2
+}
+
+// acceptQuality - returns the quality the header assigns to the value. the most
+// specific matching entry is used. returns -1 if no entry matches the value
+func acceptQuality(entries []AcceptEntry, value string) float64 {
+	quality := -1.0
+	specificity := -1
+	for _, entry := range entries {
+		if entry.Matches(value) && acceptSpecificity(entry.Value) > specificity {
+			quality = entry.Quality
+			specificity = acceptSpecificity(entry.Value)
+		}
+	}
+	return quality
+}
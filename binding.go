@@ -0,0 +1,427 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro
+
+import (
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMultipartMemory - the maximum number of bytes of a multipart form that are
+// stored in memory when binding (the remainder is stored in temporary files)
+const DefaultMultipartMemory = 32 << 20
+
+const (
+	// BindSourcePath - the value was taken from the matched path parameters
+	BindSourcePath = "path"
+	// BindSourceQuery - the value was taken from the query string
+	BindSourceQuery = "query"
+	// BindSourceHeader - the value was taken from the request headers
+	BindSourceHeader = "header"
+	// BindSourceForm - the value was taken from an urlencoded or multipart form body
+	BindSourceForm = "form"
+	// BindSourceBody - the value was decoded from a JSON or XML body
+	BindSourceBody = "body"
+	// BindSourceDefault - the value was taken from the default tag
+	BindSourceDefault = "default"
+)
+
+// bindSources - the tags that values are bound from, in precedence order
+var bindSources = []string{BindSourcePath, BindSourceQuery, BindSourceHeader, BindSourceForm}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	uuidType            = reflect.TypeOf(UUID{})
+	fileHeaderType      = reflect.TypeOf(&multipart.FileHeader{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// FieldError - describes a value that could not be bound to (or validated for) a
+// struct field
+type FieldError struct {
+	// Field - the name of the struct field
+	Field string `json:"field"`
+	// Source - where the value came from (e.g.: BindSourceQuery)
+	Source string `json:"source"`
+	// Key - the name of the value in the source (e.g.: the query parameter name)
+	Key string `json:"key,omitempty"`
+	// Value - the value that could not be used
+	Value string `json:"value,omitempty"`
+	// Rule - the validation rule that failed (empty for binding problems)
+	Rule string `json:"rule,omitempty"`
+	// Message - describes the problem
+	Message string `json:"message"`
+}
+
+// Error - implement the error interface
+func (fe FieldError) Error() string {
+	if fe.Key == "" {
+		return fmt.Sprintf("%s: %s", fe.Source, fe.Message)
+	}
+	return fmt.Sprintf("%s '%s': %s", fe.Source, fe.Key, fe.Message)
+}
+
+// FieldErrors - all of the problems found while binding a request
+type FieldErrors []FieldError
+
+// Error - implement the error interface
+func (fe FieldErrors) Error() string {
+	messages := make([]string, len(fe))
+	for idx, fieldErr := range fe {
+		messages[idx] = fieldErr.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Bind populates the struct that dst points to using the request. Fields are bound
+// using their tags:
+//
+//	path:"id"        - the matched path parameter
+//	query:"page"     - the query string value
+//	header:"X-Token" - the request header value
+//	form:"name"      - the urlencoded or multipart form value (or file)
+//	default:"10"     - the value used if no other source has one
+//
+// JSON and XML bodies are decoded (using the json and xml tags) based on the request
+// Content-Type before the other tags are applied. If a field has several tags, the
+// first source (in the order above) with a value is used. Slice fields receive all
+// of the values for the key ([]byte fields receive the bytes of the first value).
+// Values are converted to the field type (strings, bools, numbers, time.Time,
+// time.Duration, UUID, pointers and encoding.TextUnmarshaler implementations).
+// All conversion problems are returned as FieldErrors and recorded in Errors
+// (with a 400 status code). The request body can only be bound once.
+func (hc *HandlerContext) Bind(dst interface{}) error {
+	fieldErrs, bindErr := hc.bind(dst)
+	if bindErr != nil {
+		return bindErr
+	}
+	if len(fieldErrs) == 0 {
+		return nil
+	}
+	hc.Errors = append(hc.Errors, bindRoutingError(fieldErrs))
+	return fieldErrs
+}
+
+// bind - binds the request to dst and returns the field errors. an error is returned
+// if dst is not a pointer to a struct
+func (hc *HandlerContext) bind(dst interface{}) (FieldErrors, error) {
+	target := reflect.ValueOf(dst)
+	if target.Kind() != reflect.Ptr || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Bind requires a pointer to a struct but got %T", dst)
+	}
+	binder := &requestBinder{ctx: hc}
+	binder.decodeBody(dst)
+	binder.bindStruct(target.Elem())
+	return binder.errs, nil
+}
+
+// bindRoutingError - creates the RoutingError used to report binding problems
+func bindRoutingError(fieldErrs FieldErrors) RoutingError {
+	return RoutingError{
+		StatusCode: http.StatusBadRequest,
+		ErrorCode:  BindErrorCode,
+		Error:      fieldErrs,
+		Message:    "the request could not be bound",
+		Info:       ErrorInfoMap{"fields": fieldErrs},
+	}
+}
+
+// requestBinder - binds the values of a single request
+type requestBinder struct {
+	ctx   *HandlerContext
+	query url.Values
+	errs  FieldErrors
+}
+
+// decodeBody - decodes JSON and XML bodies into dst and parses form bodies so that
+// the form values can be bound
+func (rb *requestBinder) decodeBody(dst interface{}) {
+	req := rb.ctx.Request
+	if req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	var decodeErr error
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		decodeErr = json.NewDecoder(req.Body).Decode(dst)
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		decodeErr = xml.NewDecoder(req.Body).Decode(dst)
+	case mediaType == "application/x-www-form-urlencoded":
+		decodeErr = req.ParseForm()
+	case mediaType == "multipart/form-data":
+		decodeErr = req.ParseMultipartForm(DefaultMultipartMemory)
+	}
+	if decodeErr == nil || decodeErr == io.EOF {
+		return
+	}
+	fieldErr := FieldError{Source: BindSourceBody, Message: decodeErr.Error()}
+	if typeErr, ok := decodeErr.(*json.UnmarshalTypeError); ok {
+		fieldErr.Field = typeErr.Field
+		fieldErr.Key = typeErr.Field
+		fieldErr.Message = fmt.Sprintf("expected a value of type %s but got %s", typeErr.Type, typeErr.Value)
+	}
+	rb.errs = append(rb.errs, fieldErr)
+}
+
+// bindStruct - binds the tagged fields of the struct (including the fields of
+// embedded structs)
+func (rb *requestBinder) bindStruct(structValue reflect.Value) {
+	structType := structValue.Type()
+	for fieldIdx := 0; fieldIdx < structType.NumField(); fieldIdx++ {
+		field := structType.Field(fieldIdx)
+		fieldValue := structValue.Field(fieldIdx)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			rb.bindStruct(fieldValue)
+			continue
+		}
+		if field.PkgPath != "" || !fieldValue.CanSet() {
+			continue // unexported
+		}
+		rb.bindField(field, fieldValue)
+	}
+}
+
+// bindField - sets the field using the first source that has a value or, failing
+// that, the default value (if the field has not been set from the body)
+func (rb *requestBinder) bindField(field reflect.StructField, fieldValue reflect.Value) {
+	for _, source := range bindSources {
+		key := field.Tag.Get(source)
+		if key == "" || key == "-" {
+			continue
+		}
+		if source == BindSourceForm && rb.bindFormFiles(field, fieldValue, key) {
+			return
+		}
+		if source == BindSourcePath && rb.ctx.Parameters != nil &&
+			setTypedFieldValue(fieldValue, rb.ctx.Parameters.Get(key)) {
+			return
+		}
+		values := rb.sourceValues(source, key)
+		if len(values) == 0 {
+			continue
+		}
+		if setErr := setFieldValue(fieldValue, values); setErr != nil {
+			rb.errs = append(rb.errs, FieldError{Field: field.Name, Source: source, Key: key,
+				Value: strings.Join(values, ","), Message: setErr.Error()})
+		}
+		return
+	}
+	defaultValue, hasDefault := field.Tag.Lookup(BindSourceDefault)
+	if !hasDefault || !isZeroValue(fieldValue) {
+		return
+	}
+	values := []string{defaultValue}
+	if fieldValue.Kind() == reflect.Slice {
+		values = strings.Split(defaultValue, ",")
+	}
+	if setErr := setFieldValue(fieldValue, values); setErr != nil {
+		rb.errs = append(rb.errs, FieldError{Field: field.Name, Source: BindSourceDefault,
+			Value: defaultValue, Message: setErr.Error()})
+	}
+}
+
+// sourceValues - returns the values for the key from the source
+func (rb *requestBinder) sourceValues(source string, key string) []string {
+	req := rb.ctx.Request
+	switch source {
+	case BindSourcePath:
+		if rb.ctx.Parameters == nil {
+			return nil
+		}
+		return rb.ctx.Parameters.GetStrings(key)
+	case BindSourceQuery:
+		if rb.query == nil {
+			rb.query = req.URL.Query()
+		}
+		return rb.query[key]
+	case BindSourceHeader:
+		return req.Header[textproto.CanonicalMIMEHeaderKey(key)]
+	case BindSourceForm:
+		return req.PostForm[key]
+	}
+	return nil
+}
+
+// bindFormFiles - sets *multipart.FileHeader (or slice) fields using the uploaded
+// files. returns false if the field is not a file field
+func (rb *requestBinder) bindFormFiles(field reflect.StructField, fieldValue reflect.Value, key string) bool {
+	isSlice := field.Type.Kind() == reflect.Slice && field.Type.Elem() == fileHeaderType
+	if field.Type != fileHeaderType && !isSlice {
+		return false
+	}
+	form := rb.ctx.Request.MultipartForm
+	if form == nil || len(form.File[key]) == 0 {
+		return true
+	}
+	files := form.File[key]
+	if isSlice {
+		fieldValue.Set(reflect.ValueOf(files))
+	} else {
+		fieldValue.Set(reflect.ValueOf(files[0]))
+	}
+	return true
+}
+
+// setTypedFieldValue - sets the field directly using typed (already converted) path
+// parameter values (e.g.: ':ts<time>'). returns false if there are no typed values
+// or they cannot be assigned to the field, in which case the string values are used
+func setTypedFieldValue(fieldValue reflect.Value, values []interface{}) bool {
+	if len(values) == 0 {
+		return false
+	}
+	fieldType := fieldValue.Type()
+	isSlice := fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() != reflect.Uint8
+	targetType := fieldType
+	if isSlice {
+		targetType = fieldType.Elem()
+	}
+	typedValues := make([]reflect.Value, len(values))
+	for idx, value := range values {
+		if _, isString := value.(string); isString || value == nil {
+			return false
+		}
+		typed := reflect.ValueOf(value)
+		if targetType.Kind() == reflect.Ptr && typed.Type().AssignableTo(targetType.Elem()) {
+			pointer := reflect.New(targetType.Elem())
+			pointer.Elem().Set(typed)
+			typed = pointer
+		} else if !typed.Type().AssignableTo(targetType) {
+			return false
+		}
+		typedValues[idx] = typed
+	}
+	if !isSlice {
+		fieldValue.Set(typedValues[0])
+		return true
+	}
+	slice := reflect.MakeSlice(fieldType, len(typedValues), len(typedValues))
+	for idx, typed := range typedValues {
+		slice.Index(idx).Set(typed)
+	}
+	fieldValue.Set(slice)
+	return true
+}
+
+// setFieldValue - converts the values to the type of the field and sets the field.
+// all of the values are used for slices, otherwise only the first value is used.
+// byte slices are set to the bytes of the first value
+func setFieldValue(fieldValue reflect.Value, values []string) error {
+	fieldType := fieldValue.Type()
+	if fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() != reflect.Uint8 {
+		slice := reflect.MakeSlice(fieldType, len(values), len(values))
+		for idx, value := range values {
+			if setErr := setSingleValue(slice.Index(idx), value); setErr != nil {
+				return setErr
+			}
+		}
+		fieldValue.Set(slice)
+		return nil
+	}
+	return setSingleValue(fieldValue, values[0])
+}
+
+// setSingleValue - converts the value to the type of the field and sets the field
+func setSingleValue(fieldValue reflect.Value, value string) error {
+	fieldType := fieldValue.Type()
+	if fieldType.Kind() == reflect.Ptr {
+		elem := reflect.New(fieldType.Elem())
+		if setErr := setSingleValue(elem.Elem(), value); setErr != nil {
+			return setErr
+		}
+		fieldValue.Set(elem)
+		return nil
+	}
+	switch fieldType {
+	case durationType:
+		duration, parseErr := time.ParseDuration(value)
+		if parseErr != nil {
+			return invalidValueError(value, "a duration")
+		}
+		fieldValue.SetInt(int64(duration))
+		return nil
+	case timeType:
+		parsed, parseErr := parseTimeParam(value)
+		if parseErr != nil {
+			return invalidValueError(value, "a time")
+		}
+		fieldValue.Set(reflect.ValueOf(parsed))
+		return nil
+	case uuidType:
+		parsed, parseErr := ParseUUID(value)
+		if parseErr != nil {
+			return invalidValueError(value, "a UUID")
+		}
+		fieldValue.Set(reflect.ValueOf(parsed))
+		return nil
+	}
+	if fieldValue.CanAddr() && reflect.PtrTo(fieldType).Implements(textUnmarshalerType) {
+		return fieldValue.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+	switch fieldType.Kind() {
+	case reflect.String:
+		fieldValue.SetString(value)
+	case reflect.Bool:
+		parsed, parseErr := strconv.ParseBool(value)
+		if parseErr != nil {
+			return invalidValueError(value, "a boolean")
+		}
+		fieldValue.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, parseErr := strconv.ParseInt(value, 10, fieldType.Bits())
+		if parseErr != nil {
+			return invalidValueError(value, "an integer")
+		}
+		fieldValue.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, parseErr := strconv.ParseUint(value, 10, fieldType.Bits())
+		if parseErr != nil {
+			return invalidValueError(value, "a positive integer")
+		}
+		fieldValue.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, parseErr := strconv.ParseFloat(value, fieldType.Bits())
+		if parseErr != nil {
+			return invalidValueError(value, "a number")
+		}
+		fieldValue.SetFloat(parsed)
+	case reflect.Slice:
+		if fieldType.Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("unsupported field type %s", fieldType)
+		}
+		fieldValue.SetBytes([]byte(value))
+	default:
+		return fmt.Errorf("unsupported field type %s", fieldType)
+	}
+	return nil
+}
+
+// invalidValueError - describes a value that could not be converted
+func invalidValueError(value string, expected string) error {
+	return fmt.Errorf("'%s' is not %s", value, expected)
+}
+
+// isZeroValue - returns true if the value is the zero value for its type
+func isZeroValue(value reflect.Value) bool {
+	return reflect.DeepEqual(value.Interface(), reflect.Zero(value.Type()).Interface())
+}
@@ -0,0 +1,156 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/theyakka/goro"
+)
+
+type pagination struct {
+	Page  int  `query:"page" default:"1"`
+	Limit *int `query:"limit"`
+}
+
+type updateUserRequest struct {
+	pagination
+	ID      int           `path:"id"`
+	Tags    []string      `query:"tag"`
+	Token   string        `header:"X-Token"`
+	Name    string        `json:"name" xml:"name" form:"name"`
+	Age     int           `json:"age" xml:"age" form:"age"`
+	Timeout time.Duration `query:"timeout" default:"30s"`
+	Active  bool          `query:"active" default:"true"`
+}
+
+func TestBindRequest(t *testing.T) {
+	var bound updateUserRequest
+	var bindErr error
+	var bindCtx *goro.HandlerContext
+	bindRouter := goro.NewRouter()
+	bindRouter.Match([]string{"PUT", "POST"}, "/users/:id<int>").HandleFunc(func(ctx *goro.HandlerContext) {
+		bound = updateUserRequest{}
+		bindErr = ctx.Bind(&bound)
+		bindCtx = ctx
+	})
+	serveBindRequest := func(method string, url string, contentType string, body string) {
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("X-Token", "secret")
+		bindRouter.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	serveBindRequest("PUT", "/users/42?tag=a&tag=b&limit=5", "application/json", `{"name":"bob","age":30}`)
+	if bindErr != nil {
+		t.Fatal("Expected the request to bind but got", bindErr)
+	}
+	if bound.ID != 42 || bound.Name != "bob" || bound.Age != 30 || bound.Token != "secret" {
+		t.Error("Expected the path, body and header values to be bound but got", bound)
+	}
+	if len(bound.Tags) != 2 || bound.Tags[1] != "b" || bound.Limit == nil || *bound.Limit != 5 {
+		t.Error("Expected the query values to be bound but got", bound.Tags, bound.Limit)
+	}
+	if bound.Page != 1 || bound.Timeout != 30*time.Second || !bound.Active {
+		t.Error("Expected the default values to be used but got", bound.Page, bound.Timeout, bound.Active)
+	}
+
+	serveBindRequest("POST", "/users/7?page=3&active=false", "application/x-www-form-urlencoded", "name=alice&age=25")
+	if bindErr != nil || bound.Name != "alice" || bound.Age != 25 || bound.Page != 3 || bound.Active {
+		t.Error("Expected the form and query values to be bound but got", bound, bindErr)
+	}
+
+	serveBindRequest("PUT", "/users/8", "application/xml", "<user><name>carol</name><age>41</age></user>")
+	if bindErr != nil || bound.Name != "carol" || bound.Age != 41 {
+		t.Error("Expected the XML body to be bound but got", bound, bindErr)
+	}
+
+	serveBindRequest("PUT", "/users/9?page=two&timeout=soon", "application/json", `{"name":"dave"}`)
+	fieldErrs, ok := bindErr.(goro.FieldErrors)
+	if !ok || len(fieldErrs) != 2 {
+		t.Fatal("Expected 2 field errors but got", bindErr)
+	}
+	if fieldErrs[0].Field != "Page" || fieldErrs[0].Source != goro.BindSourceQuery || fieldErrs[0].Value != "two" {
+		t.Error("Unexpected field error", fieldErrs[0])
+	}
+	routingErr := bindCtx.ErrorForStatus(http.StatusBadRequest)
+	if routingErr.ErrorCode != goro.BindErrorCode || routingErr.Error == nil {
+		t.Error("Expected the bind errors to be recorded in the context but got", bindCtx.Errors)
+	}
+	if bound.Name != "dave" {
+		t.Error("Expected the valid values to be bound but got", bound.Name)
+	}
+
+	if bindErr := goro.NewHandlerContext(nil, nil, nil).Bind(bound); bindErr == nil {
+		t.Error("Expected an error when binding to a non-pointer")
+	}
+}
+
+type reportRequest struct {
+	At    time.Time  `path:"ts"`
+	Since *time.Time `path:"ts"`
+	Count int        `path:"count"`
+	Limit int64      `path:"count"`
+}
+
+func TestBindTypedPathParameters(t *testing.T) {
+	var bound reportRequest
+	var bindErr error
+	bindRouter := goro.NewRouter()
+	bindRouter.GET("/reports/:ts<time>/:count<int>").HandleFunc(func(ctx *goro.HandlerContext) {
+		bound = reportRequest{}
+		bindErr = ctx.Bind(&bound)
+	})
+	execMockRequest(bindRouter, "GET", "/reports/2019-03-01T10:00:00Z/25")
+	expectedTime := time.Date(2019, 3, 1, 10, 0, 0, 0, time.UTC)
+	if bindErr != nil {
+		t.Fatal("Expected the typed path parameters to bind but got", bindErr)
+	}
+	if !bound.At.Equal(expectedTime) || bound.Since == nil || !bound.Since.Equal(expectedTime) {
+		t.Error("Expected the time parameter to be bound but got", bound.At, bound.Since)
+	}
+	if bound.Count != 25 || bound.Limit != 25 {
+		t.Error("Expected the int parameter to be bound but got", bound.Count, bound.Limit)
+	}
+}
+
+type uploadRequest struct {
+	Payload  []byte  `query:"payload"`
+	Checksum *[]byte `header:"X-Checksum"`
+	Origin   net.IP  `query:"origin"`
+}
+
+func TestBindBytes(t *testing.T) {
+	var bound uploadRequest
+	var bindErr error
+	bindRouter := goro.NewRouter()
+	bindRouter.POST("/uploads").HandleFunc(func(ctx *goro.HandlerContext) {
+		bound = uploadRequest{}
+		bindErr = ctx.Bind(&bound)
+	})
+	req, _ := http.NewRequest("POST", "/uploads?payload=hello&payload=world&origin=10.0.0.1", nil)
+	req.Header.Set("X-Checksum", "abc")
+	bindRouter.ServeHTTP(httptest.NewRecorder(), req)
+	if bindErr != nil {
+		t.Fatal("Expected the byte values to bind but got", bindErr)
+	}
+	if string(bound.Payload) != "hello" || bound.Checksum == nil || string(*bound.Checksum) != "abc" {
+		t.Error("Expected the raw bytes of the first value but got", bound.Payload, bound.Checksum)
+	}
+	// byte slices implementing encoding.TextUnmarshaler are unmarshaled
+	if !bound.Origin.Equal(net.IPv4(10, 0, 0, 1)) {
+		t.Error("Expected the IP address to be unmarshaled but got", bound.Origin)
+	}
+}
@@ -1,7 +1,74 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
 package goro
 
+import (
+	"path"
+	"strings"
+)
+
+// Bundle is a self-contained module that can be mounted on a Router. A bundle
+// declares its own routes (relative to the location it is mounted at) as well as
+// filters, error handlers and variables that only apply to those routes.
 type Bundle interface {
-	PreFilters() []*Filter
-	PostFilters() []*Filter
+	// Routes - the routes provided by the bundle
 	Routes() []*Route
+
+	// Filters - filters that will be executed for the bundle routes only
+	Filters() []Filter
+
+	// ErrorHandlers - status code specific error handlers for the bundle routes.
+	// If no handler is defined for a status code, the Router handlers will be used
+	ErrorHandlers() map[int]ContextHandler
+
+	// Variables - variables used to resolve the bundle route paths. Bundle variables
+	// take precedence over the Router variables
+	Variables() map[string]string
+}
+
+// AddBundle mounts the bundle at the root of the Router
+func (r *Router) AddBundle(bundle Bundle) []*Route {
+	return r.AddBundleWithPrefix("", bundle)
+}
+
+// AddBundleWithPrefix mounts the bundle on the Router. prefix will be prepended to
+// all of the bundle routes. The bundle routes are copied so that a bundle can be
+// mounted more than once
+func (r *Router) AddBundleWithPrefix(prefix string, bundle Bundle) []*Route {
+	filters := adaptFilters(bundle.Filters())
+	errorHandlers := bundle.ErrorHandlers()
+	variables := map[string]string{}
+	for variable, value := range bundle.Variables() {
+		if !strings.HasPrefix(variable, "$") {
+			variable = "$" + variable
+		}
+		variables[variable] = value
+	}
+	var mountedRoutes []*Route
+	for _, route := range bundle.Routes() {
+		mountedRoute := route.copyWithPath(path.Join("/", prefix, route.PathFormat))
+		mountedRoute.filters = append(append([]HaltingFilter{}, filters...), route.filters...)
+		mountedRoute.errorHandlers = errorHandlers
+		mountedRoute.variables = variables
+		mountedRoutes = append(mountedRoutes, mountedRoute)
+	}
+	return r.Use(mountedRoutes...)
+}
+
+// AddBundle mounts the bundle at the root of the Router for the subdomain pattern.
+// If a Router has not been registered for the subdomain(s), one will be created
+func (dm *DomainMap) AddBundle(subdomainPattern string, bundle Bundle) *Router {
+	router := dm.routerMap[domainMapKey(strings.Split(subdomainPattern, "|")[0])]
+	if router == nil {
+		router = dm.NewRouter(subdomainPattern)
+	}
+	router.AddBundle(bundle)
+	return router
 }
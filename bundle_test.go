@@ -0,0 +1,90 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/theyakka/goro"
+)
+
+type testBundle struct {
+	filter *countingFilter
+}
+
+func (tb testBundle) Routes() []*goro.Route {
+	return []*goro.Route{
+		goro.NewRoute("GET", "/").HandleFunc(testHandler),
+		goro.NewRoute("GET", "/users/$section").HandleFunc(testHandler),
+		goro.NewRoute("GET", "/missing").HandleFunc(func(ctx *goro.HandlerContext) {
+			goro.ServeFile(ctx, "/goro/does/not/exist.txt", http.StatusOK)
+		}),
+	}
+}
+
+func (tb testBundle) Filters() []goro.Filter {
+	return []goro.Filter{tb.filter}
+}
+
+func (tb testBundle) ErrorHandlers() map[int]goro.ContextHandler {
+	return map[int]goro.ContextHandler{
+		http.StatusNotFound: goro.ContextHandlerFunc(func(ctx *goro.HandlerContext) {
+			wasHit = true
+		}),
+	}
+}
+
+func (tb testBundle) Variables() map[string]string {
+	return map[string]string{"section": "active"}
+}
+
+type countingFilter struct {
+	before int
+	after  int
+}
+
+func (cf *countingFilter) ExecuteBefore(ctx *goro.HandlerContext) {
+	cf.before++
+}
+
+func (cf *countingFilter) ExecuteAfter(ctx *goro.HandlerContext) {
+	cf.after++
+}
+
+func TestBundleWithPrefix(t *testing.T) {
+	filter := &countingFilter{}
+	bundleRouter := goro.NewRouter()
+	bundleRouter.GET("/other").HandleFunc(testHandler)
+	bundleRouter.AddBundleWithPrefix("/admin", testBundle{filter: filter})
+	expectHitResult(t, bundleRouter, "GET", "/admin")
+	expectHitResult(t, bundleRouter, "GET", "/admin/users/active")
+	if filter.before != 2 || filter.after != 2 {
+		t.Error("Expected the bundle filter to run twice but got", filter.before, filter.after)
+	}
+	expectHitResult(t, bundleRouter, "GET", "/other")
+	expectNotHitResult(t, bundleRouter, "GET", "/users/active")
+	if filter.before != 2 {
+		t.Error("Expected the bundle filter to only apply to bundle routes")
+	}
+	// the bundle error handler should be used for bundle routes only
+	expectHitResult(t, bundleRouter, "GET", "/admin/missing")
+	if errs := bundleRouter.Validate(); len(errs) > 0 {
+		t.Error("Expected the bundle routes to be valid but got", errs)
+	}
+}
+
+func TestBundleOnSubdomain(t *testing.T) {
+	filter := &countingFilter{}
+	domains := goro.NewDomainMap("localhost.local")
+	domains.AddBundle("admin", testBundle{filter: filter})
+	expectHitResult(t, domains, "GET", "http://admin.localhost.local/users/active")
+	expectNotHitResult(t, domains, "GET", "http://www.localhost.local/users/active")
+}
@@ -10,23 +10,35 @@
 package goro
 
 import (
-	"hash/fnv"
 	"sync"
+	"sync/atomic"
 )
 
-// RouteCache - temporary storage for routes
+// RouteCache - temporary storage for matched routes. The cache is a least recently
+// used (LRU) cache made up of a map (for lookup) and a doubly linked list (for
+// ordering) so that all operations are O(1).
 type RouteCache struct {
+	// hits / misses / evictions - counters (accessed atomically so they must be
+	// the first fields to guarantee alignment)
+	hits      uint64
+	misses    uint64
+	evictions uint64
+
+	// generation - incremented every time the cache is cleared
+	generation uint64
+
 	// mutex - locking
-	mutex sync.RWMutex
+	mutex sync.Mutex
 
-	// containsMap - indexed positions (starting at 1) for each of the hashed path values
-	containsMap map[uint32]bool
+	// items - cached items keyed by method and then by path
+	items map[string]map[string]*cacheItem
 
-	// pathHashes - ordered list of pathHashes
-	pathHashes []uint32
+	// head / tail - the most and least recently used items
+	head *cacheItem
+	tail *cacheItem
 
-	// Entries - ordered list of cache entries
-	Entries []CacheEntry
+	// count - the number of items in the cache
+	count int
 
 	// MaxEntries - maximum number of items permitted in the cache
 	MaxEntries int
@@ -37,115 +49,177 @@ type RouteCache struct {
 
 // CacheEntry - an entry in the route cache
 type CacheEntry struct {
-	hasValue bool
-	Params   map[string]interface{}
-	Route    *Route
+	hasValue      bool
+	Node          *Node
+	Params        *Parameters
+	CatchAllValue string
+}
+
+// CacheStats - route cache usage information
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Entries   int
+}
+
+// cacheItem - a linked list item that holds a cache entry
+type cacheItem struct {
+	method string
+	path   string
+	entry  CacheEntry
+	prev   *cacheItem
+	next   *cacheItem
 }
 
 // NewRouteCache - creates a new default RouteCache
 func NewRouteCache() *RouteCache {
 	return &RouteCache{
-		pathHashes:      []uint32{},
-		Entries:         []CacheEntry{},
-		containsMap:     map[uint32]bool{},
+		items:           map[string]map[string]*cacheItem{},
 		MaxEntries:      100,
 		ReorderOnAccess: true,
 	}
 }
 
 // Get - fetch a cache entry (if exists)
-func (rc *RouteCache) Get(path string) CacheEntry {
-	if rc.ReorderOnAccess {
-		rc.mutex.Lock()
-		defer rc.mutex.Unlock()
-	} else {
-		rc.mutex.RLock()
-		defer rc.mutex.RUnlock()
-	}
-	cacheEntry := CacheEntry{}
-	hash := fnv.New32a()
-	if _, writeErr := hash.Write([]byte(path)); writeErr != nil {
+func (rc *RouteCache) Get(method string, path string) CacheEntry {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+	item := rc.items[method][path]
+	if item == nil || item.method != method || item.path != path {
+		atomic.AddUint64(&rc.misses, 1)
 		return NotFoundCacheEntry()
 	}
-	pathHash := hash.Sum32()
-	var foundIdx = -1
-	for idx, hashKey := range rc.pathHashes {
-		if hashKey == pathHash {
-			cacheEntry = rc.Entries[idx]
-			foundIdx = idx
-			break
-		}
-	}
-	if foundIdx >= 0 && rc.ReorderOnAccess {
-		if len(rc.pathHashes) > 1 {
-			rc.moveEntryToTop(pathHash, foundIdx)
-		}
-	} else {
-		cacheEntry = NotFoundCacheEntry()
+	if rc.ReorderOnAccess {
+		rc.moveItemToTop(item)
 	}
-	return cacheEntry
+	atomic.AddUint64(&rc.hits, 1)
+	return item.entry
 }
 
-func (rc *RouteCache) moveEntryToTop(pathHash uint32, moveIndex int) {
-	allHashes := rc.pathHashes
-	allEntries := rc.Entries
-	entry := allEntries[moveIndex]
-	// remove entry
-	lastIndex := moveIndex + 1
-	allHashes = append(allHashes[:moveIndex], allHashes[lastIndex:]...)
-	allEntries = append(allEntries[:moveIndex], allEntries[lastIndex:]...)
-	// re-add entry
-	newHashes := append([]uint32{pathHash}, allHashes...)
-	newEntries := append([]CacheEntry{entry}, allEntries...)
-	rc.pathHashes = newHashes
-	rc.Entries = newEntries
+// Generation - returns a value that changes every time the cache is cleared
+func (rc *RouteCache) Generation() uint64 {
+	return atomic.LoadUint64(&rc.generation)
 }
 
-// PutRoute - add a route into the route cache
-func (rc *RouteCache) PutRoute(path string, route *Route) {
-	entry := CacheEntry{
-		Route: route,
-	}
-	rc.Put(path, entry)
+// Put - add an item to the route cache
+func (rc *RouteCache) Put(method string, path string, entry CacheEntry) {
+	rc.PutForGeneration(rc.Generation(), method, path, entry)
 }
 
-// Put - add an item to the route cache
-func (rc *RouteCache) Put(path string, entry CacheEntry) {
+// PutForGeneration - add an item to the route cache only if the cache has not been
+// cleared since the generation was obtained (using Generation). This prevents
+// entries created using out of date routes from being stored
+func (rc *RouteCache) PutForGeneration(generation uint64, method string, path string, entry CacheEntry) {
+	if rc.MaxEntries <= 0 {
+		return
+	}
 	rc.mutex.Lock()
 	defer rc.mutex.Unlock()
-
-	hash := fnv.New32a()
-	if _, writeErr := hash.Write([]byte(path)); writeErr != nil {
+	if atomic.LoadUint64(&rc.generation) != generation {
 		return
 	}
-	allHashes := rc.pathHashes
-	allEntries := rc.Entries
-	pathHash := hash.Sum32()
-	if rc.containsMap[pathHash] {
-		return // don't add it again
+	entry.hasValue = true
+	if item := rc.items[method][path]; item != nil {
+		item.entry = entry
+		rc.moveItemToTop(item)
+		return
 	}
-	if len(allHashes) == rc.MaxEntries {
-		// remove the last element from the slices
-		removeHash := allHashes[len(allHashes)-1]
-		allHashes = allHashes[:len(allHashes)-1]
-		allEntries = allEntries[:len(allEntries)-1]
-		delete(rc.containsMap, removeHash)
+	for rc.count >= rc.MaxEntries {
+		rc.removeItem(rc.tail)
+		atomic.AddUint64(&rc.evictions, 1)
 	}
-
-	newHashes := append([]uint32{pathHash}, allHashes...)
-	newEntries := append([]CacheEntry{entry}, allEntries...)
-	rc.containsMap[pathHash] = true
-	rc.pathHashes = newHashes
-	rc.Entries = newEntries
+	item := &cacheItem{
+		method: method,
+		path:   path,
+		entry:  entry,
+	}
+	methodItems := rc.items[method]
+	if methodItems == nil {
+		methodItems = map[string]*cacheItem{}
+		rc.items[method] = methodItems
+	}
+	methodItems[path] = item
+	rc.pushItem(item)
+	rc.count++
 }
 
 // Clear - reset the cache
 func (rc *RouteCache) Clear() {
 	rc.mutex.Lock()
 	defer rc.mutex.Unlock()
-	rc.pathHashes = []uint32{}
-	rc.Entries = []CacheEntry{}
-	rc.containsMap = map[uint32]bool{}
+	atomic.AddUint64(&rc.generation, 1)
+	rc.items = map[string]map[string]*cacheItem{}
+	rc.head = nil
+	rc.tail = nil
+	rc.count = 0
+}
+
+// Len - the number of entries in the cache
+func (rc *RouteCache) Len() int {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+	return rc.count
+}
+
+// Stats - returns the cache usage counters
+func (rc *RouteCache) Stats() CacheStats {
+	return CacheStats{
+		Hits:      atomic.LoadUint64(&rc.hits),
+		Misses:    atomic.LoadUint64(&rc.misses),
+		Evictions: atomic.LoadUint64(&rc.evictions),
+		Entries:   rc.Len(),
+	}
+}
+
+// moveItemToTop - marks the item as the most recently used
+func (rc *RouteCache) moveItemToTop(item *cacheItem) {
+	if rc.head == item {
+		return
+	}
+	rc.unlinkItem(item)
+	rc.pushItem(item)
+}
+
+// pushItem - adds the item to the top of the list
+func (rc *RouteCache) pushItem(item *cacheItem) {
+	item.prev = nil
+	item.next = rc.head
+	if rc.head != nil {
+		rc.head.prev = item
+	}
+	rc.head = item
+	if rc.tail == nil {
+		rc.tail = item
+	}
+}
+
+// unlinkItem - removes the item from the list
+func (rc *RouteCache) unlinkItem(item *cacheItem) {
+	if item.prev != nil {
+		item.prev.next = item.next
+	} else {
+		rc.head = item.next
+	}
+	if item.next != nil {
+		item.next.prev = item.prev
+	} else {
+		rc.tail = item.prev
+	}
+	item.prev = nil
+	item.next = nil
+}
+
+// removeItem - removes the item from the list and the lookup map
+func (rc *RouteCache) removeItem(item *cacheItem) {
+	rc.unlinkItem(item)
+	delete(rc.items[item.method], item.path)
+	rc.count--
+}
+
+// HasValue - returns true if the entry was found in the cache
+func (ce CacheEntry) HasValue() bool {
+	return ce.hasValue
 }
 
 // NotFoundCacheEntry - represents the inability to find an entry in the cache
@@ -0,0 +1,79 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro_test
+
+import (
+	"testing"
+
+	"github.com/theyakka/goro"
+)
+
+func TestRouteCacheLRU(t *testing.T) {
+	cache := goro.NewRouteCache()
+	cache.MaxEntries = 2
+	cache.Put("GET", "/a", goro.CacheEntry{CatchAllValue: "a"})
+	cache.Put("GET", "/b", goro.CacheEntry{CatchAllValue: "b"})
+	// access '/a' so that '/b' becomes the least recently used
+	if entry := cache.Get("GET", "/a"); !entry.HasValue() || entry.CatchAllValue != "a" {
+		t.Error("Expected a cache entry for /a")
+	}
+	cache.Put("GET", "/c", goro.CacheEntry{CatchAllValue: "c"})
+	if cache.Get("GET", "/b").HasValue() {
+		t.Error("Expected /b to have been evicted")
+	}
+	if !cache.Get("GET", "/a").HasValue() || !cache.Get("GET", "/c").HasValue() {
+		t.Error("Expected /a and /c to be cached")
+	}
+	if cache.Get("POST", "/a").HasValue() {
+		t.Error("Expected entries to be keyed by method")
+	}
+	stats := cache.Stats()
+	if stats.Hits != 3 || stats.Misses != 2 || stats.Evictions != 1 || stats.Entries != 2 {
+		t.Errorf("Unexpected cache stats %+v", stats)
+	}
+}
+
+func TestRouterUsesRouteCache(t *testing.T) {
+	var paramValue string
+	cachedRouter := goro.NewRouter()
+	cachedRouter.GET("/users/:id").HandleFunc(func(ctx *goro.HandlerContext) {
+		paramValue = ctx.Parameters.GetFirstString("id")
+	})
+	execMockRequest(cachedRouter, "GET", "/users/42")
+	execMockRequest(cachedRouter, "GET", "/users/42")
+	if paramValue != "42" {
+		t.Error("Expected the cached parameters to be used but got", paramValue)
+	}
+	stats := cachedRouter.CacheStats()
+	if stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("Expected 1 hit and 1 miss but got %+v", stats)
+	}
+	// registering a route should invalidate the cache
+	cachedRouter.GET("/users/me").HandleFunc(testHandler)
+	if cachedRouter.CacheStats().Entries != 0 {
+		t.Error("Expected the cache to be cleared when a route is added")
+	}
+	expectHitResult(t, cachedRouter, "GET", "/users/me")
+}
+
+func TestCachedParametersAreNotShared(t *testing.T) {
+	var paramValue string
+	cachedRouter := goro.NewRouter()
+	cachedRouter.GET("/users/:id").HandleFunc(func(ctx *goro.HandlerContext) {
+		paramValue = ctx.Parameters.GetFirstString("id")
+		// changing the returned values must not affect later requests
+		ctx.Parameters.Get("id")[0] = "changed"
+	})
+	execMockRequest(cachedRouter, "GET", "/users/42")
+	execMockRequest(cachedRouter, "GET", "/users/42")
+	if paramValue != "42" || cachedRouter.CacheStats().Hits != 1 {
+		t.Error("Expected the cached parameters to be unchanged but got", paramValue)
+	}
+}
@@ -21,6 +21,10 @@ var logger *log.Logger
 // RootPath - string representation of the root path
 const RootPath = "/"
 
+// MethodAny - the method used to register a route that handles all HTTP methods.
+// Routes registered for a specific method take precedence
+const MethodAny = "*"
+
 // RouteComponentType - route component types
 // NOTE: variables will be stripped out / replaced so we dont track them
 type RouteComponentType int
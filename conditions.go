@@ -0,0 +1,206 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// routeConditions - request attributes that must be present for a Route to be
+// selected. Conditions are evaluated after the path has been matched
+type routeConditions struct {
+	headers  []conditionPair
+	queries  []conditionPair
+	consumes []string
+	produces []string
+	schemes  []string
+}
+
+// conditionPair - a key and (optional) value that must be present in the request
+type conditionPair struct {
+	key   string
+	value string
+}
+
+// Headers adds a condition that the request must have the header values. Values
+// are passed as key / value pairs (e.g.: "X-API-Version", "2"). An empty value (or
+// a key without a value) only requires the header to be present
+func (rte *Route) Headers(pairs ...string) *Route {
+	for _, pair := range conditionPairs(pairs) {
+		pair.key = http.CanonicalHeaderKey(pair.key)
+		rte.conditions.headers = append(rte.conditions.headers, pair)
+	}
+	rte.conditionsChanged()
+	return rte
+}
+
+// Queries adds a condition that the request must have the query string values.
+// Values are passed as key / value pairs (e.g.: "format", "csv"). An empty value
+// (or a key without a value) only requires the query parameter to be present
+func (rte *Route) Queries(pairs ...string) *Route {
+	rte.conditions.queries = append(rte.conditions.queries, conditionPairs(pairs)...)
+	rte.conditionsChanged()
+	return rte
+}
+
+// Consumes adds a condition that the request Content-Type must be one of the media
+// types (e.g.: "application/json" or "text/*"). If no route can consume the request
+// a 415 (Unsupported Media Type) error will be emitted
+func (rte *Route) Consumes(mediaTypes ...string) *Route {
+	for _, mediaType := range mediaTypes {
+		rte.conditions.consumes = append(rte.conditions.consumes, strings.ToLower(mediaType))
+	}
+	rte.conditionsChanged()
+	return rte
+}
+
+// Produces adds a condition that the request must accept (using the Accept header)
+// one of the media types. If no route produces an acceptable response a 406 (Not
+// Acceptable) error will be emitted
+func (rte *Route) Produces(mediaTypes ...string) *Route {
+	for _, mediaType := range mediaTypes {
+		rte.conditions.produces = append(rte.conditions.produces, strings.ToLower(mediaType))
+	}
+	rte.conditionsChanged()
+	return rte
+}
+
+// Schemes adds a condition that the request must have been made using one of the
+// schemes (e.g.: "https")
+func (rte *Route) Schemes(schemes ...string) *Route {
+	for _, scheme := range schemes {
+		rte.conditions.schemes = append(rte.conditions.schemes, strings.ToLower(scheme))
+	}
+	rte.conditionsChanged()
+	return rte
+}
+
+// conditionsChanged - checks that changing the conditions of a registered route did
+// not make it a duplicate of another route (see Router.SetStrictRegistration)
+func (rte *Route) conditionsChanged() {
+	if rte.router != nil {
+		rte.router.checkRouteConditions(rte)
+	}
+}
+
+// HasConditions returns true if the Route has any request conditions
+func (rte *Route) HasConditions() bool {
+	rc := rte.conditions
+	return len(rc.headers) > 0 || len(rc.queries) > 0 || len(rc.consumes) > 0 ||
+		len(rc.produces) > 0 || len(rc.schemes) > 0
+}
+
+// conditionsKey - a string that uniquely identifies the route conditions (used to
+// detect duplicate routes)
+func (rte *Route) conditionsKey() string {
+	rc := rte.conditions
+	return fmt.Sprintf("%v|%v|%v|%v|%v", rc.headers, rc.queries, rc.consumes, rc.produces, rc.schemes)
+}
+
+// conditionPairs - splits a list of keys and values into pairs
+func conditionPairs(pairs []string) []conditionPair {
+	var conditions []conditionPair
+	for idx := 0; idx < len(pairs); idx += 2 {
+		condition := conditionPair{key: pairs[idx]}
+		if idx+1 < len(pairs) {
+			condition.value = pairs[idx+1]
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions
+}
+
+// checkConditions - returns zero if the request satisfies all of the route's
+// conditions. Otherwise returns the status code for the first failed condition:
+// 404 for schemes, headers and queries, 415 for consumes and 406 for produces
+func (rte *Route) checkConditions(req *http.Request) int {
+	rc := rte.conditions
+	if len(rc.schemes) > 0 && !containsString(rc.schemes, requestScheme(req)) {
+		return http.StatusNotFound
+	}
+	for _, header := range rc.headers {
+		values, exists := req.Header[header.key]
+		if !exists || (header.value != "" && !containsString(values, header.value)) {
+			return http.StatusNotFound
+		}
+	}
+	if len(rc.queries) > 0 {
+		query := req.URL.Query()
+		for _, queryCondition := range rc.queries {
+			values, exists := query[queryCondition.key]
+			if !exists || (queryCondition.value != "" && !containsString(values, queryCondition.value)) {
+				return http.StatusNotFound
+			}
+		}
+	}
+	if len(rc.consumes) > 0 {
+		mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		consumable := false
+		for _, consumes := range rc.consumes {
+			if mediaType != "" && (AcceptEntry{Value: consumes}).Matches(mediaType) {
+				consumable = true
+				break
+			}
+		}
+		if !consumable {
+			return http.StatusUnsupportedMediaType
+		}
+	}
+	if len(rc.produces) > 0 {
+		accept := req.Header.Get("Accept")
+		if accept == "" {
+			return 0
+		}
+		entries := ParseAccept(accept)
+		for _, produces := range rc.produces {
+			if acceptQuality(entries, produces) > 0 {
+				return 0
+			}
+		}
+		return http.StatusNotAcceptable
+	}
+	return 0
+}
+
+// conditionFailurePriority - ranks the condition failure status codes so that the
+// failure for the route that was the closest match is reported
+func conditionFailurePriority(statusCode int) int {
+	switch statusCode {
+	case http.StatusUnsupportedMediaType:
+		return 1
+	case http.StatusNotAcceptable:
+		return 2
+	}
+	return 0
+}
+
+// requestScheme - returns the scheme that was used to make the request
+func requestScheme(req *http.Request) string {
+	if req.URL.Scheme != "" {
+		return strings.ToLower(req.URL.Scheme)
+	}
+	if req.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// containsString - returns true if the value is in the list
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/theyakka/goro"
+)
+
+func TestRouteConditions(t *testing.T) {
+	hitName := ""
+	namedHandler := func(name string) goro.ContextHandlerFunc {
+		return func(ctx *goro.HandlerContext) {
+			hitName = name
+		}
+	}
+	conditionRouter := goro.NewRouter()
+	conditionRouter.GET("/reports").HandleFunc(namedHandler("default"))
+	conditionRouter.GET("/reports").Queries("format", "csv").HandleFunc(namedHandler("csv"))
+	conditionRouter.GET("/reports").Headers("X-API-Version", "2").HandleFunc(namedHandler("v2"))
+	conditionRouter.POST("/upload").Consumes("application/json").HandleFunc(namedHandler("json-upload"))
+	conditionRouter.POST("/upload").Consumes("image/*").HandleFunc(namedHandler("image-upload"))
+	conditionRouter.GET("/data").Produces("application/json").HandleFunc(namedHandler("json-data"))
+	conditionRouter.GET("/secure").Schemes("https").HandleFunc(namedHandler("secure"))
+	if errs := conditionRouter.Validate(); len(errs) > 0 {
+		t.Error("Expected routes with different conditions to be valid but got", errs)
+	}
+
+	requests := []struct {
+		method   string
+		path     string
+		headers  map[string]string
+		secure   bool
+		status   int
+		expected string
+	}{
+		{"GET", "/reports", nil, false, http.StatusOK, "default"},
+		{"GET", "/reports?format=csv", nil, false, http.StatusOK, "csv"},
+		{"GET", "/reports", map[string]string{"X-Api-Version": "2"}, false, http.StatusOK, "v2"},
+		{"GET", "/reports", map[string]string{"X-Api-Version": "3"}, false, http.StatusOK, "default"},
+		{"POST", "/upload", map[string]string{"Content-Type": "application/json; charset=utf-8"}, false, http.StatusOK, "json-upload"},
+		{"POST", "/upload", map[string]string{"Content-Type": "image/png"}, false, http.StatusOK, "image-upload"},
+		{"POST", "/upload", map[string]string{"Content-Type": "text/plain"}, false, http.StatusUnsupportedMediaType, ""},
+		{"GET", "/data", map[string]string{"Accept": "text/html;q=0.9, application/*"}, false, http.StatusOK, "json-data"},
+		{"GET", "/data", nil, false, http.StatusOK, "json-data"},
+		{"GET", "/data", map[string]string{"Accept": "text/html, application/json;q=0"}, false, http.StatusNotAcceptable, ""},
+		{"GET", "/secure", nil, false, http.StatusNotFound, ""},
+		{"GET", "/secure", nil, true, http.StatusOK, "secure"},
+	}
+	for _, request := range requests {
+		hitName = ""
+		req, _ := http.NewRequest(request.method, request.path, nil)
+		for header, value := range request.headers {
+			req.Header.Set(header, value)
+		}
+		if request.secure {
+			req.TLS = &tls.ConnectionState{}
+		}
+		w := httptest.NewRecorder()
+		conditionRouter.ServeHTTP(w, req)
+		if w.Code != request.status || hitName != request.expected {
+			t.Error("Expected", request.method, request.path, request.headers, "to return",
+				request.status, request.expected, "but got", w.Code, hitName)
+		}
+	}
+}
@@ -10,8 +10,10 @@
 package goro
 
 import (
+	"context"
 	"net/http"
 	"sync"
+	"time"
 )
 
 const (
@@ -26,38 +28,121 @@ type HandlerContext struct {
 	Parameters     *Parameters
 	Meta           map[string]interface{}
 	Path           string
+	OriginalPath   string
 	CatchAllValue  string
 	Errors         []RoutingError
 	router         *Router
-	state          map[string]interface{}
+	route          *Route
+	state          map[interface{}]interface{}
 	internalState  map[string]interface{}
+	abortResult    *FilterResult
+	parentContext  context.Context
+	mountPrefix    string
 }
 
+// handlerContextKey - the context key used to look up the HandlerContext
+type handlerContextKey struct{}
+
+// parametersContextKey - the context key used to look up the matched Parameters
+type parametersContextKey struct{}
+
+// NewHandlerContext - creates a new HandlerContext for the request. The request is
+// replaced with a copy that uses the HandlerContext as its context so that the
+// matched parameters and state are available to anything using Request.Context()
 func NewHandlerContext(request *http.Request, responseWriter http.ResponseWriter, router *Router) *HandlerContext {
-	return &HandlerContext{
+	originalPath := ""
+	if request != nil && request.URL != nil {
+		originalPath = request.URL.Path
+	}
+	hc := &HandlerContext{
+		OriginalPath:   originalPath,
 		Request:        request,
 		ResponseWriter: responseWriter,
 		router:         router,
 		Meta:           map[string]interface{}{},
-		state:          map[string]interface{}{},
+		state:          map[interface{}]interface{}{},
 		internalState:  map[string]interface{}{},
+		parentContext:  context.Background(),
+	}
+	if request != nil {
+		hc.parentContext = request.Context()
+		hc.Request = request.WithContext(hc)
+	}
+	return hc
+}
+
+// HandlerContextFromContext - returns the HandlerContext that the context belongs to
+// (e.g.: using Request.Context()) or nil if there isn't one
+func HandlerContextFromContext(ctx context.Context) *HandlerContext {
+	hc, _ := ctx.Value(handlerContextKey{}).(*HandlerContext)
+	return hc
+}
+
+// ParametersFromContext - returns the matched Parameters for the request that the
+// context belongs to or nil if there are none
+func ParametersFromContext(ctx context.Context) *Parameters {
+	params, _ := ctx.Value(parametersContextKey{}).(*Parameters)
+	return params
+}
+
+// Deadline - implement the context.Context interface (delegates to the request)
+func (hc *HandlerContext) Deadline() (deadline time.Time, ok bool) {
+	return hc.parentContext.Deadline()
+}
+
+// Done - implement the context.Context interface (delegates to the request). The
+// channel is closed when the client disconnects or the request is cancelled
+func (hc *HandlerContext) Done() <-chan struct{} {
+	return hc.parentContext.Done()
+}
+
+// Err - implement the context.Context interface (delegates to the request)
+func (hc *HandlerContext) Err() error {
+	return hc.parentContext.Err()
+}
+
+// Value - implement the context.Context interface. State values take precedence over
+// the values of the original request context. The HandlerContext and Parameters can
+// be retrieved using HandlerContextFromContext and ParametersFromContext
+func (hc *HandlerContext) Value(key interface{}) interface{} {
+	switch key.(type) {
+	case handlerContextKey:
+		return hc
+	case parametersContextKey:
+		return hc.Parameters
+	}
+	hc.RLock()
+	value, hasValue := hc.state[key]
+	hc.RUnlock()
+	if hasValue {
+		return value
 	}
+	return hc.parentContext.Value(key)
 }
 
-func (hc *HandlerContext) SetState(key string, value interface{}) {
+// CatchAllSegments - returns the path segments matched by the catch-all (e.g.:
+// 'a/b/c' => []string{"a", "b", "c"}). Empty if the catch-all matched nothing
+func (hc *HandlerContext) CatchAllSegments() []string {
+	return splitPathSegments(hc.CatchAllValue)
+}
+
+// SetState - stores a value for the request. Keys can be of any comparable type;
+// packages should define their own key types (as with context.WithValue) to avoid
+// collisions. State values are also available using Request.Context().Value
+func (hc *HandlerContext) SetState(key interface{}, value interface{}) {
 	hc.Lock()
 	hc.state[key] = value
 	hc.Unlock()
 }
 
-func (hc *HandlerContext) GetState(key string) interface{} {
+func (hc *HandlerContext) GetState(key interface{}) interface{} {
 	hc.RLock()
 	state := hc.state[key]
 	hc.RUnlock()
 	return state
 }
 
-func (hc *HandlerContext) GetStateString(key string) string {
+func (hc *HandlerContext) GetStateString(key interface{}) string {
 	stateVal := hc.GetState(key)
 	if stateString, ok := stateVal.(string); ok {
 		return stateString
@@ -65,7 +150,7 @@ func (hc *HandlerContext) GetStateString(key string) string {
 	return ""
 }
 
-func (hc *HandlerContext) GetStateInt(key string) int {
+func (hc *HandlerContext) GetStateInt(key interface{}) int {
 	stateVal := hc.GetState(key)
 	if stateString, ok := stateVal.(int); ok {
 		return stateString
@@ -73,12 +158,25 @@ func (hc *HandlerContext) GetStateInt(key string) int {
 	return 0
 }
 
-func (hc *HandlerContext) ClearState(key string) {
+func (hc *HandlerContext) ClearState(key interface{}) {
 	hc.Lock()
-	hc.state[key] = nil
+	delete(hc.state, key)
 	hc.Unlock()
 }
 
+// Abort - stops the request from being dispatched any further. It is intended to be
+// called from a Filter. If no response has been written, the router will emit an
+// error for the status code (if not zero). The reason is recorded in Errors
+func (hc *HandlerContext) Abort(statusCode int, reason string) {
+	result := FilterHalt(statusCode, reason)
+	hc.abortResult = &result
+}
+
+// IsAborted - returns true if Abort has been called
+func (hc *HandlerContext) IsAborted() bool {
+	return hc.abortResult != nil
+}
+
 func (hc *HandlerContext) HasError() bool {
 	return len(hc.Errors) > 0
 }
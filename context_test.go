@@ -0,0 +1,69 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/theyakka/goro"
+)
+
+type userStateKey struct{}
+
+type traceContextKey struct{}
+
+func TestHandlerContextAsContext(t *testing.T) {
+	var requestCtx context.Context
+	var handlerCtx *goro.HandlerContext
+	contextRouter := goro.NewRouter()
+	contextRouter.GET("/users/:id").HandleFunc(func(ctx *goro.HandlerContext) {
+		ctx.SetState(userStateKey{}, "bob")
+		handlerCtx = ctx
+		requestCtx = ctx.Request.Context()
+	})
+
+	parentCtx, cancel := context.WithCancel(context.WithValue(context.Background(), traceContextKey{}, "trace-1"))
+	req, _ := http.NewRequest("GET", "/users/42", nil)
+	contextRouter.ServeHTTP(httptest.NewRecorder(), req.WithContext(parentCtx))
+	if requestCtx == nil {
+		t.Fatal("Expected the route to be hit")
+	}
+	if requestCtx.Value(userStateKey{}) != "bob" || handlerCtx.GetStateString(userStateKey{}) != "bob" {
+		t.Error("Expected the state to be available from the request context")
+	}
+	if requestCtx.Value(traceContextKey{}) != "trace-1" {
+		t.Error("Expected the original request context values to be available")
+	}
+	if params := goro.ParametersFromContext(requestCtx); params == nil || params.GetFirstString("id") != "42" {
+		t.Error("Expected the matched parameters to be available from the request context")
+	}
+	if goro.HandlerContextFromContext(requestCtx) != handlerCtx {
+		t.Error("Expected the handler context to be available from the request context")
+	}
+	handlerCtx.ClearState(userStateKey{})
+	if requestCtx.Value(userStateKey{}) != nil {
+		t.Error("Expected the cleared state to be removed")
+	}
+	if handlerCtx.Err() != nil {
+		t.Error("Expected no error before the request is cancelled but got", handlerCtx.Err())
+	}
+	cancel()
+	select {
+	case <-requestCtx.Done():
+	default:
+		t.Error("Expected the handler context to be cancelled with the request")
+	}
+	if handlerCtx.Err() != context.Canceled {
+		t.Error("Expected context.Canceled but got", handlerCtx.Err())
+	}
+}
@@ -56,14 +56,7 @@ func (dm *DomainMap) NewRouter(subdomainPattern string) *Router {
 	router := NewRouter()
 	subdomains := strings.Split(subdomainPattern, "|")
 	for _, subdomain := range subdomains {
-		key := subdomain
-		if subdomain == "*" {
-			key = DomainMapWildcardSubdomainKey
-			dm.hasWildcard = true
-		} else if subdomain == "<*>" {
-			key = DomainMapNakedSubdomainKey
-		}
-		dm.AddRouter(key, router)
+		dm.AddRouter(domainMapKey(subdomain), router)
 	}
 	return router
 }
@@ -73,22 +66,28 @@ func (dm *DomainMap) NewRouter(subdomainPattern string) *Router {
 func (dm *DomainMap) NewRouters(subdomains ...string) []*Router {
 	var routers []*Router
 	for _, subdomain := range subdomains {
-		key := subdomain
-		if subdomain == "*" {
-			key = DomainMapWildcardSubdomainKey
-			dm.hasWildcard = true
-		} else if subdomain == "<*>" {
-			key = DomainMapNakedSubdomainKey
-		}
 		router := NewRouter()
-		dm.AddRouter(key, router)
+		dm.AddRouter(domainMapKey(subdomain), router)
 		routers = append(routers, router)
 	}
 	return routers
 }
 
+// domainMapKey - returns the key used to store the router for a subdomain
+func domainMapKey(subdomain string) string {
+	if subdomain == "*" {
+		return DomainMapWildcardSubdomainKey
+	} else if subdomain == "<*>" {
+		return DomainMapNakedSubdomainKey
+	}
+	return subdomain
+}
+
 // AddRouter - Register a router for a domain pattern (regex)
 func (dm *DomainMap) AddRouter(subdomain string, router *Router) {
+	if subdomain == DomainMapWildcardSubdomainKey {
+		dm.hasWildcard = true
+	}
 	dm.subdomains = append(dm.subdomains, subdomain)
 	dm.routerMap[subdomain] = router
 }
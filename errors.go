@@ -41,4 +41,11 @@ const (
 	RouterContentErrorCode
 	// ChainHadError - a router chain dispatched an error
 	ChainGenericErrorCode
+	// FilterHaltedErrorCode - a filter halted the request before it was dispatched
+	FilterHaltedErrorCode
+	// BindErrorCode - the request values could not be bound (see HandlerContext.Bind)
+	BindErrorCode
+	// ValidationErrorCode - the request values failed validation (see
+	// HandlerContext.Validate)
+	ValidationErrorCode
 )
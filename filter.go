@@ -9,9 +9,86 @@
 
 package goro
 
+import "fmt"
+
 // Filter is an interface that can be registered on the Router to apply custom
-// logic to modify the Request or calling Context
+// logic to modify the Request or calling Context. A Filter can stop the request
+// from being dispatched by calling HandlerContext.Abort
 type Filter interface {
 	ExecuteBefore(ctx *HandlerContext)
 	ExecuteAfter(ctx *HandlerContext)
 }
+
+// HaltingFilter is a filter that reports whether the request should continue to
+// be dispatched. If Before returns a halting result, no further filters or handlers
+// will be executed, however After will still be called for all filters
+type HaltingFilter interface {
+	Before(ctx *HandlerContext) FilterResult
+	After(ctx *HandlerContext)
+}
+
+// FilterResult - the result of executing a HaltingFilter before dispatch
+type FilterResult struct {
+	// Halt - if true, the request will not be dispatched any further
+	Halt bool
+
+	// StatusCode - if the filter has not written a response, the router will emit
+	// an error with this status code
+	StatusCode int
+
+	// Reason - why the filter halted the request
+	Reason string
+}
+
+// FilterContinue - returns a result that allows the request to be dispatched
+func FilterContinue() FilterResult {
+	return FilterResult{}
+}
+
+// FilterHalt - returns a result that stops the request from being dispatched
+func FilterHalt(statusCode int, reason string) FilterResult {
+	return FilterResult{
+		Halt:       true,
+		StatusCode: statusCode,
+		Reason:     reason,
+	}
+}
+
+// filterAdapter - allows a Filter to be used as a HaltingFilter
+type filterAdapter struct {
+	filter Filter
+}
+
+// AdaptFilter - wraps a Filter so that it can be used as a HaltingFilter. The
+// request will be halted if the Filter calls HandlerContext.Abort
+func AdaptFilter(filter Filter) HaltingFilter {
+	return filterAdapter{filter: filter}
+}
+
+// Before - implement the HaltingFilter interface
+func (fa filterAdapter) Before(ctx *HandlerContext) FilterResult {
+	fa.filter.ExecuteBefore(ctx)
+	return FilterContinue()
+}
+
+// After - implement the HaltingFilter interface
+func (fa filterAdapter) After(ctx *HandlerContext) {
+	fa.filter.ExecuteAfter(ctx)
+}
+
+// adaptFilters - wraps all of the filters using AdaptFilter
+func adaptFilters(filters []Filter) []HaltingFilter {
+	adapted := make([]HaltingFilter, len(filters))
+	for idx, filter := range filters {
+		adapted[idx] = AdaptFilter(filter)
+	}
+	return adapted
+}
+
+// filterName - returns the type name of the filter (unwrapping adapted filters)
+func filterName(filter HaltingFilter) string {
+	if adapter, ok := filter.(filterAdapter); ok {
+		return fmt.Sprintf("%T", adapter.filter)
+	}
+	return fmt.Sprintf("%T", filter)
+}
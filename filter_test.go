@@ -0,0 +1,96 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/theyakka/goro"
+)
+
+type authFilter struct {
+	after int
+}
+
+func (af *authFilter) Before(ctx *goro.HandlerContext) goro.FilterResult {
+	if ctx.Request.Header.Get("Authorization") == "" {
+		return goro.FilterHalt(http.StatusUnauthorized, "missing authorization")
+	}
+	return goro.FilterContinue()
+}
+
+func (af *authFilter) After(ctx *goro.HandlerContext) {
+	af.after++
+}
+
+type abortingFilter struct{}
+
+func (abortingFilter) ExecuteBefore(ctx *goro.HandlerContext) {
+	ctx.ResponseWriter.WriteHeader(http.StatusForbidden)
+	ctx.Abort(http.StatusForbidden, "forbidden")
+}
+
+func (abortingFilter) ExecuteAfter(ctx *goro.HandlerContext) {
+}
+
+func TestHaltingFilter(t *testing.T) {
+	filter := &authFilter{}
+	var errors []goro.RoutingError
+	filterRouter := goro.NewRouter()
+	filterRouter.AddHaltingFilter(filter)
+	filterRouter.SetErrorHandler(http.StatusUnauthorized, goro.ContextHandlerFunc(func(ctx *goro.HandlerContext) {
+		errors = ctx.Errors
+		ctx.ResponseWriter.WriteHeader(http.StatusUnauthorized)
+	}))
+	filterRouter.GET("/secret").HandleFunc(testHandler)
+
+	req, _ := http.NewRequest("GET", "/secret", nil)
+	w := httptest.NewRecorder()
+	filterRouter.ServeHTTP(w, req)
+	if wasHit {
+		t.Error("Expected the handler to NOT be hit after the filter halted")
+	}
+	if w.Code != http.StatusUnauthorized {
+		t.Error("Expected status 401 but got", w.Code)
+	}
+	if len(errors) != 1 || errors[0].ErrorCode != goro.FilterHaltedErrorCode ||
+		errors[0].Message != "missing authorization" {
+		t.Error("Expected the halt reason to be recorded but got", errors)
+	}
+	if filter.after != 1 {
+		t.Error("Expected post filters to run once but ran", filter.after)
+	}
+	resetState()
+
+	req.Header.Set("Authorization", "Bearer token")
+	filterRouter.ServeHTTP(httptest.NewRecorder(), req)
+	if !wasHit {
+		t.Error("Expected the handler to be hit when authorized")
+	}
+	resetState()
+}
+
+func TestFilterAbort(t *testing.T) {
+	errorHandlerHit := false
+	filterRouter := goro.NewRouter()
+	filterRouter.SetErrorHandler(http.StatusForbidden, goro.ContextHandlerFunc(func(ctx *goro.HandlerContext) {
+		errorHandlerHit = true
+	}))
+	filterRouter.GET("/admin").HandleFunc(testHandler).Filter(abortingFilter{})
+	filterRouter.GET("/public").HandleFunc(testHandler)
+
+	expectNotHitResult(t, filterRouter, "GET", "/admin")
+	if errorHandlerHit {
+		t.Error("Expected no error to be emitted when the filter already wrote a response")
+	}
+	expectHitResult(t, filterRouter, "GET", "/public")
+}
@@ -9,28 +9,107 @@
 
 package goro
 
-import "path"
+import (
+	"path"
+	"strings"
+)
 
 type Group struct {
-	prefix string
-	router *Router
+	prefix     string
+	name       string
+	parent     *Group
+	router     *Router
+	filters    []HaltingFilter
+	middleware []ChainHandler
 }
 
 func NewGroup(prefix string, router *Router) *Group {
 	return &Group{
 		prefix: prefix,
+		name:   groupNameForPrefix(prefix),
 		router: router,
 	}
 }
 
+// Group creates a sub-group. The sub-group inherits the filters and chain handlers
+// of this group (and its parents)
 func (g *Group) Group(prefix string) *Group {
 	fullPrefix := path.Join(g.prefix, prefix)
-	return NewGroup(fullPrefix, g.router)
+	group := NewGroup(fullPrefix, g.router)
+	group.name = groupNameForPrefix(prefix)
+	group.parent = g
+	return group
+}
+
+// Name overrides the name that will be prepended to the names of routes created
+// in the group (and any sub-groups created after this call). By default the name
+// is derived from the prefix (e.g.: '/api/v1' => 'api.v1')
+func (g *Group) Name(name string) *Group {
+	g.name = name
+	return g
+}
+
+// FullName returns the name of the group including the names of any parent groups
+func (g *Group) FullName() string {
+	if g.parent == nil {
+		return g.name
+	}
+	parentName := g.parent.FullName()
+	if parentName == "" {
+		return g.name
+	}
+	if g.name == "" {
+		return parentName
+	}
+	return parentName + "." + g.name
+}
+
+// AddFilter adds a filter that will be executed for all routes in the group (and
+// any sub-groups). Group filters are executed after the Router filters
+func (g *Group) AddFilter(filter Filter) *Group {
+	g.filters = append(g.filters, AdaptFilter(filter))
+	return g
+}
+
+// AddHaltingFilter adds a filter, that can stop the request from being dispatched,
+// to all routes in the group (and any sub-groups)
+func (g *Group) AddHaltingFilter(filter HaltingFilter) *Group {
+	g.filters = append(g.filters, filter)
+	return g
+}
+
+// Use adds chain handlers that will be executed before the handler of every route in
+// the group (and any sub-groups)
+func (g *Group) Use(handlers ...ChainHandler) *Group {
+	g.middleware = append(g.middleware, handlers...)
+	return g
+}
+
+// Filters returns the filters that apply to the group, starting with the filters
+// of the outermost parent group
+func (g *Group) Filters() []HaltingFilter {
+	if g.parent == nil {
+		return g.filters
+	}
+	filters := append([]HaltingFilter{}, g.parent.Filters()...)
+	return append(filters, g.filters...)
+}
+
+// ChainHandlers returns the chain handlers that apply to the group, starting with the
+// handlers of the outermost parent group
+func (g *Group) ChainHandlers() []ChainHandler {
+	if g.parent == nil {
+		return g.middleware
+	}
+	handlers := append([]ChainHandler{}, g.parent.ChainHandlers()...)
+	return append(handlers, g.middleware...)
 }
 
 // Add creates a new Route and registers the instance within the Router
 func (g *Group) Add(method string, routePath string) *Route {
 	route := NewRoute(method, path.Join(g.prefix, routePath))
+	route.namePrefix = g.FullName()
+	route.group = g
 	return g.router.Use(route)[0]
 }
 
@@ -68,3 +147,30 @@ func (g *Group) PATCH(routePath string) *Route {
 func (g *Group) OPTIONS(routePath string) *Route {
 	return g.Add("OPTIONS", routePath)
 }
+
+// Any creates a new Route (within the group) that handles all methods. See Router.Any
+func (g *Group) Any(routePath string) *Route {
+	return g.Add(MethodAny, routePath)
+}
+
+// Match creates a single Route (within the group) that is registered for each of the
+// methods. See Router.Match
+func (g *Group) Match(methods []string, routePath string) *Route {
+	route := newRouteForMethods(methods, path.Join(g.prefix, routePath))
+	route.namePrefix = g.FullName()
+	route.group = g
+	return g.router.Use(route)[0]
+}
+
+// groupNameForPrefix - derives a dotted group name from a path prefix. For
+// example, '/api/v1' => 'api.v1'
+func groupNameForPrefix(prefix string) string {
+	var nameParts []string
+	for _, part := range strings.Split(prefix, "/") {
+		part = strings.TrimLeft(part, ":*$")
+		if part != "" {
+			nameParts = append(nameParts, part)
+		}
+	}
+	return strings.Join(nameParts, ".")
+}
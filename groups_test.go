@@ -0,0 +1,74 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/theyakka/goro"
+)
+
+type orderFilter struct {
+	name  string
+	order *[]string
+}
+
+func (of orderFilter) ExecuteBefore(ctx *goro.HandlerContext) {
+	*of.order = append(*of.order, of.name)
+}
+
+func (of orderFilter) ExecuteAfter(ctx *goro.HandlerContext) {
+}
+
+func TestGroupFilters(t *testing.T) {
+	var order []string
+	groupRouter := goro.NewRouter()
+	groupRouter.AddFilter(orderFilter{name: "router", order: &order})
+	adminGroup := groupRouter.Group("/admin").AddFilter(orderFilter{name: "admin", order: &order})
+	usersGroup := adminGroup.Group("/users").AddFilter(orderFilter{name: "users", order: &order})
+	usersGroup.GET("/:id").HandleFunc(func(ctx *goro.HandlerContext) {
+		order = append(order, "handler")
+	}).Filter(orderFilter{name: "route", order: &order})
+	groupRouter.GET("/public").HandleFunc(func(ctx *goro.HandlerContext) {
+		order = append(order, "handler")
+	})
+
+	execMockRequest(groupRouter, "GET", "/admin/users/42")
+	expected := "router,admin,users,route,handler"
+	if strings.Join(order, ",") != expected {
+		t.Error("Expected filter order", expected, "but got", strings.Join(order, ","))
+	}
+	order = nil
+	execMockRequest(groupRouter, "GET", "/public")
+	if strings.Join(order, ",") != "router,handler" {
+		t.Error("Expected only the router filter to run but got", strings.Join(order, ","))
+	}
+}
+
+func TestGroupChainHandlers(t *testing.T) {
+	var order []string
+	groupRouter := goro.NewRouter()
+	apiGroup := groupRouter.Group("/api").Use(func(ch *goro.Chain, ctx *goro.HandlerContext) {
+		order = append(order, "cors")
+		ch.Next(ctx)
+	})
+	v1Group := apiGroup.Group("/v1").Use(func(ch *goro.Chain, ctx *goro.HandlerContext) {
+		order = append(order, "v1")
+		ch.Next(ctx)
+	})
+	v1Group.GET("/stats").HandleFunc(func(ctx *goro.HandlerContext) {
+		order = append(order, "handler")
+	})
+	execMockRequest(groupRouter, "GET", "/api/v1/stats")
+	if strings.Join(order, ",") != "cors,v1,handler" {
+		t.Error("Expected chain order cors,v1,handler but got", strings.Join(order, ","))
+	}
+}
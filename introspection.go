@@ -0,0 +1,178 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro
+
+import (
+	"strconv"
+	"strings"
+)
+
+// RouteInfo - describes a registered route. Routes registered for several methods
+// are described once for each method
+type RouteInfo struct {
+	// Method - the method the route is registered for (MethodAny for all methods)
+	Method string
+
+	// Pattern - the path format the route was declared with (including the prefix of
+	// any mounted Router or API version)
+	Pattern string
+
+	// ResolvedPattern - the pattern with all variables substituted
+	ResolvedPattern string
+
+	// Name - the fully qualified name of the route (empty if the route is not named)
+	Name string
+
+	// Description - the description of the route (see Route.Describe)
+	Description string
+
+	// Meta - the meta values of the route. Must be treated as read-only
+	Meta map[string]interface{}
+
+	// Filters - the filters executed for the route (Router, Group and Route filters,
+	// in execution order)
+	Filters []HaltingFilter
+
+	// Group - the group the route was created in (nil if none)
+	Group *Group
+
+	// Route - the registered route
+	Route *Route
+
+	// label - describes the API version of the route (if any)
+	label string
+}
+
+// FilterNames - returns the type names of the filters executed for the route
+func (ri RouteInfo) FilterNames() []string {
+	return filterNames(ri.Filters)
+}
+
+// Walk calls fn for every registered route (including the routes of mounted Routers
+// and API versions). Routes are visited in tree order with the routes that share a
+// path ordered by method. Walking stops at the first error returned by fn, which is
+// then returned by Walk
+func (r *Router) Walk(fn func(info RouteInfo) error) error {
+	return r.walkRouteInfo("", "", nil, fn)
+}
+
+// Routes returns the descriptions of all registered routes (see Walk)
+func (r *Router) Routes() []RouteInfo {
+	var routes []RouteInfo
+	r.Walk(func(info RouteInfo) error {
+		routes = append(routes, info)
+		return nil
+	})
+	return routes
+}
+
+// routeMethodKey - identifies a route registered for a method
+type routeMethodKey struct {
+	route  *Route
+	method string
+}
+
+// walkRouteInfo - walks the routes of the router with the path prefix, label and
+// the filters inherited from the parent routers (for mounted routers). routes with
+// optional parts are stored at more than one node but are only visited once for
+// each method
+func (r *Router) walkRouteInfo(prefix string, label string, inherited []HaltingFilter,
+	fn func(info RouteInfo) error) error {
+	seen := map[routeMethodKey]bool{}
+	var walkNodes func(nodes []*Node) error
+	walkNodes = func(nodes []*Node) error {
+		for _, node := range nodes {
+			for _, method := range node.methods() {
+				for _, route := range node.routes[method] {
+					key := routeMethodKey{route: route, method: method}
+					if seen[key] {
+						continue
+					}
+					seen[key] = true
+					if walkErr := r.walkRoute(method, route, prefix, label, inherited, fn); walkErr != nil {
+						return walkErr
+					}
+				}
+			}
+			if walkErr := walkNodes(node.nodes); walkErr != nil {
+				return walkErr
+			}
+		}
+		return nil
+	}
+	return walkNodes(r.Tree().nodes)
+}
+
+// walkRoute - calls fn for the route and then walks any Router or API versions that
+// are mounted at the route (which inherit the filters of the route)
+func (r *Router) walkRoute(method string, route *Route, prefix string, label string,
+	inherited []HaltingFilter, fn func(info RouteInfo) error) error {
+	info := r.routeInfo(method, route, prefix, label, inherited)
+	if walkErr := fn(info); walkErr != nil {
+		return walkErr
+	}
+	mountPrefix := prefix + strings.TrimSuffix(route.PathFormat, "/*")
+	switch mounted := route.mount.(type) {
+	case *Router:
+		return mounted.walkRouteInfo(mountPrefix, label, info.Filters, fn)
+	case *Versioned:
+		for _, version := range mounted.versions {
+			versionPrefix := mountPrefix + "/v" + strconv.Itoa(version.number)
+			if walkErr := version.router.walkRouteInfo(versionPrefix, version.label(), info.Filters, fn); walkErr != nil {
+				return walkErr
+			}
+		}
+	}
+	return nil
+}
+
+// routeInfo - describes the route. inherited are the filters of any parent routers
+func (r *Router) routeInfo(method string, route *Route, prefix string, label string,
+	inherited []HaltingFilter) RouteInfo {
+	filters := append(append([]HaltingFilter{}, inherited...), r.filters...)
+	resolvedPattern := route.PathFormat
+	var resolvedParts []string
+	for _, part := range strings.Split(route.PathFormat, "/") {
+		if isVariablePart(part) {
+			resolved, resolveErr := resolveVariableComponent(part, r.variablesForRoute(route))
+			if resolveErr != nil {
+				resolvedParts = nil
+				break
+			}
+			part = strings.TrimPrefix(resolved, "/")
+		}
+		resolvedParts = append(resolvedParts, part)
+	}
+	if resolvedParts != nil {
+		resolvedPattern = strings.Join(resolvedParts, "/")
+	}
+	name, _ := route.Info[RouteInfoKeyName].(string)
+	description, _ := route.Info[RouteInfoKeyDescription].(string)
+	return RouteInfo{
+		Method:          method,
+		Pattern:         prefixedPattern(prefix, route.PathFormat),
+		ResolvedPattern: prefixedPattern(prefix, resolvedPattern),
+		Name:            name,
+		Description:     description,
+		Meta:            route.Meta,
+		Filters:         append(filters, route.Filters()...),
+		Group:           route.group,
+		Route:           route,
+		label:           label,
+	}
+}
+
+// prefixedPattern - returns the pattern with the mount prefix (if any) prepended
+func prefixedPattern(prefix string, pattern string) string {
+	if prefix == "" {
+		return pattern
+	}
+	return strings.TrimSuffix(prefix+pattern, "/")
+}
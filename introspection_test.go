@@ -0,0 +1,75 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/theyakka/goro"
+)
+
+func TestRoutesInfo(t *testing.T) {
+	infoRouter := goro.NewRouter()
+	infoRouter.SetStringVariable("$section", "reports")
+	infoRouter.AddHaltingFilter(&authFilter{})
+	api := infoRouter.Group("/api")
+	api.GET("/$section/:id").Name("report").Describe("shows a report")
+	infoRouter.Match([]string{"GET", "POST"}, "/forms").Meta["public"] = true
+	childRouter := goro.NewRouter()
+	childRouter.AddFilter(orderFilter{})
+	childRouter.GET("/status")
+	infoRouter.Mount("/admin", childRouter)
+	infoRouter.GET("/docs/:lang?/:page")
+	infoRouter.GET("/a/:x?/:y?/:z?")
+
+	routes := infoRouter.Routes()
+	byKey := map[string]goro.RouteInfo{}
+	for _, info := range routes {
+		byKey[info.Method+" "+info.Pattern] = info
+	}
+	if len(routes) != 7 {
+		t.Error("Expected 7 routes but got", len(routes), byKey)
+	}
+	if _, found := byKey["GET /a/:x?/:y?/:z?"]; !found {
+		t.Error("Expected the optional route to be listed once but got", byKey)
+	}
+	report := byKey["GET /api/$section/:id"]
+	if report.ResolvedPattern != "/api/reports/:id" || report.Name != "api.report" ||
+		report.Description != "shows a report" || report.Group == nil || report.Group.FullName() != "api" {
+		t.Error("Unexpected report route info", report)
+	}
+	if names := report.FilterNames(); len(names) != 1 || names[0] != "*goro_test.authFilter" {
+		t.Error("Expected the router filter to be listed but got", names)
+	}
+	forms := byKey["POST /forms"]
+	if forms.Route == nil || forms.Route != byKey["GET /forms"].Route || forms.Meta["public"] != true {
+		t.Error("Expected GET and POST to share the forms route and metadata")
+	}
+	status, found := byKey["GET /admin/status"]
+	if !found {
+		t.Error("Expected the mounted router routes to be listed under the prefix but got", byKey)
+	}
+	// the filters of the parent router are executed before those of the mounted router
+	if names := status.FilterNames(); len(names) != 2 || names[0] != "*goro_test.authFilter" {
+		t.Error("Expected the parent router filter to be listed first but got", names)
+	}
+
+	// walking stops at the first error
+	stopErr := errors.New("stop")
+	visited := 0
+	walkErr := infoRouter.Walk(func(info goro.RouteInfo) error {
+		visited++
+		return stopErr
+	})
+	if walkErr != stopErr || visited != 1 {
+		t.Error("Expected walking to stop after the first route but visited", visited, walkErr)
+	}
+}
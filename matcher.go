@@ -13,56 +13,86 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
+// defaultParamsCapacity - the number of parameters pooled matches have room for
+// before they need to grow
+const defaultParamsCapacity = 8
+
 // Matcher is the global matching engine
 type Matcher struct {
 	router             *Router
 	LogMatchTime       bool
 	FallbackToCatchAll bool
+
+	// CaseSensitive - if true, fixed path segments must match the declared case
+	CaseSensitive bool
+
+	// matchPool - pool of Match instances so that matching does not allocate
+	matchPool sync.Pool
 }
 
 // Match represents a matched node in the tree
 type Match struct {
 	Node          *Node
-	Params        map[string][]string
 	CatchAllValue string
-	ParentMatch   *Match
+
+	// params - the matched wildcard values (in path order)
+	params []matchParam
+
+	// path - the path being matched (without leading or trailing slashes)
+	path string
+
+	// caseSensitive - if true, fixed parts are compared using an exact match
+	caseSensitive bool
+
+	// fullPath - the path being matched (without the leading slash)
+	fullPath string
+
+	// catch-all fallback information recorded while walking the tree
+	catchAllNode   *radixNode
+	catchAllPos    int
+	catchAllParams []matchParam
 }
 
-// NewMatch creates a new Match instance
-func NewMatch(node *Node) *Match {
-	return &Match{
-		Node:          node,
-		Params:        map[string][]string{},
-		CatchAllValue: "",
-	}
+// matchParam - a matched wildcard value
+type matchParam struct {
+	node  *Node
+	key   string
+	value string
 }
 
-// NewMatchWithParent creates a new instance of a match that passes down the values from
-// the parent match
-func NewMatchWithParent(node *Node, parentMatch *Match) *Match {
-	match := &Match{
-		Node:          node,
-		Params:        map[string][]string{},
-		CatchAllValue: "",
+// Parameters - returns the matched wildcard values converted to their declared types
+func (match *Match) Parameters() *Parameters {
+	paramsMap := map[string][]interface{}{}
+	for _, param := range match.params {
+		converted, _ := param.node.paramType.Convert(param.value)
+		paramsMap[param.key] = append(paramsMap[param.key], converted)
 	}
-	match.ParentMatch = parentMatch
-	if parentMatch != nil {
-		match.Params = map[string][]string{}
-		// need to copy
-		for key, value := range parentMatch.Params {
-			match.Params[key] = value
-		}
-		match.CatchAllValue = parentMatch.CatchAllValue
-	}
-	return match
+	return NewParameters(paramsMap)
 }
 
 func (match *Match) String() string {
+	part := ""
+	if match.Node != nil {
+		part = match.Node.part
+	}
 	return fmt.Sprintf("goro.Match # part=%s, wc=%v, ca=%v",
-		match.Node.part, match.Params, match.CatchAllValue)
+		part, match.params, match.CatchAllValue)
+}
+
+// reset - clears the match so that it can be reused
+func (match *Match) reset(path string) {
+	match.Node = nil
+	match.CatchAllValue = ""
+	match.params = match.params[:0]
+	match.fullPath = path
+	match.path = strings.TrimSuffix(path, "/")
+	match.catchAllNode = nil
+	match.catchAllPos = -1
+	match.catchAllParams = match.catchAllParams[:0]
 }
 
 // NewMatcher creates a new instance of the Matcher
@@ -71,197 +101,120 @@ func NewMatcher(router *Router) *Matcher {
 		router:             router,
 		LogMatchTime:       false,
 		FallbackToCatchAll: true,
+		matchPool: sync.Pool{
+			New: func() interface{} {
+				return &Match{
+					params:         make([]matchParam, 0, defaultParamsCapacity),
+					catchAllParams: make([]matchParam, 0, defaultParamsCapacity),
+				}
+			},
+		},
 	}
 }
 
-// MatchPathToRoute attempts to match the given path to a registered Route
+// MatchPathToRoute attempts to match the given path to a registered Route. The
+// returned Match can be handed back using ReleaseMatch once it is no longer needed.
+//
+// At each level of the path, the parts are checked in the following order (the
+// order that routes were registered in never affects the result):
+//
+//  1. parts leading to routes with a higher priority (see Route.Priority)
+//  2. fixed parts (e.g.: '/users/me')
+//  3. wildcards containing fixed text (e.g.: ':name.:ext')
+//  4. wildcards with both a type and a regular expression (e.g.: ':id<int>(^1)')
+//  5. wildcards with a type or a regular expression (e.g.: ':id<int>')
+//  6. wildcards without constraints (e.g.: ':name')
+//  7. catch-alls (e.g.: '/files/*' or '/files/+path')
+//
+// Parts with the same precedence are checked in alphabetical order. If a part
+// matches but none of the parts below it do, the next part is checked. Catch-alls
+// are only used if no other route matches the full path (with deeper catch-alls
+// taking precedence over shallower ones), unless they have a higher priority.
 func (m *Matcher) MatchPathToRoute(method string, path string, req *http.Request) *Match {
-
-	startTime := time.Now()
-	tree := m.router.routes
-	if tree == nil {
-		return nil // no routes registered for this method
-	}
-
-	nodesToCheck := tree.nodes
-	var currentMatches []*Match
-	candidate := NewMatchCandidate(path)
-	if path == RootPath {
-		candidate.part = RootPath
+	var startTime time.Time
+	if m.LogMatchTime {
+		startTime = time.Now()
 	}
-	finalMatches := []*Match{}
-	catchAlls := []*Match{}
-	// loop until no more match candidates
-	for candidate != NoMatchCandidate() {
-		var matches []*Match
-		var catchAllMatches []*Match
-		if currentMatches == nil {
-			matches, catchAllMatches, _ = m.matchNodesForCandidate(method, candidate, nodesToCheck)
-			// Log("++", candidate.part)
-			// Log("++", matches, catchAllMatches)
-		} else {
-			matches, catchAllMatches, _ = m.matchCurrentMatchesForCandidate(method, candidate, currentMatches)
-			// Log("--", candidate.part)
-			// Log("--", matches)
+	match := m.matchPool.Get().(*Match)
+	for _, root := range m.rootsForMethod(m.router.routeIndex(), method) {
+		if root == nil {
+			continue
 		}
-		if len(catchAllMatches) > 0 {
-			// append the old catch alls to the new ones so the deeper matches take precedent
-			catchAlls = append(catchAllMatches, catchAlls...)
-		}
-		if len(matches) == 0 {
+		match.reset(strings.TrimPrefix(path, "/"))
+		match.caseSensitive = m.CaseSensitive
+		match.matchRoot(root)
+		if match.Node != nil {
 			break
 		}
-		currentMatches = matches
-		if !candidate.HasRemainingCandidates() {
-			finalMatches = append(finalMatches, matches...)
-			break
-		} else {
-			candidate = candidate.NextCandidate()
-		}
 	}
-
-	// fmt.Println("")
-	// fmt.Println("")
-	// Log("-----")
-	// Log("final matches: ", finalMatches)
-	// Log("catch alls:    ", catchAlls)
-
-	var matchToUse *Match
-	if len(finalMatches) > 0 {
-		if !m.FallbackToCatchAll {
-			matchToUse = finalMatches[0]
-		} else {
-			// check to see if the match contains a route that matches the requested method.
-			// if not, fallback to a catch-all route (if one is matched)
-			for _, match := range finalMatches {
-				if match.Node.RouteForMethod(method) != nil {
-					matchToUse = match
-					break
-				}
-			}
-		}
-	}
-
-	if matchToUse == nil && len(catchAlls) > 0 {
-		matchToUse = catchAlls[0]
-	}
-
-	// fmt.Println("")
-	// Log("-----")
-	// Log("final match:   ", matchToUse)
-
 	if m.LogMatchTime {
-		endTime := time.Now()
-		Log("Matched in", endTime.Sub(startTime))
+		Log("Matched in", time.Since(startTime))
 	}
-	return matchToUse
-}
-
-func (m *Matcher) matchNodesForCandidate(method string, candidate MatchCandidate, nodes []*Node) (matches []*Match, catchalls []*Match, errorCode int) {
-	if candidate != NoMatchCandidate() {
-		return m.checkNodesForMatches(method, candidate, nodes, nil)
+	if match.Node == nil {
+		m.ReleaseMatch(match)
+		return nil
 	}
-	return []*Match{}, []*Match{}, 0
+	return match
 }
 
-func (m *Matcher) matchCurrentMatchesForCandidate(method string, candidate MatchCandidate, currentMatches []*Match) (matches []*Match, catchalls []*Match, errorCode int) {
-	matchedNodes := []*Match{}
-	catchAllNodes := []*Match{}
-	errCode := 0
-	for _, match := range currentMatches {
-		node := match.Node
-		if node.HasChildren() {
-			nodeMatches, nodeCatchAlls, matchErrCode := m.checkNodesForMatches(method, candidate, node.nodes, match)
-			matchedNodes = append(matchedNodes, nodeMatches...)
-			catchAllNodes = append(catchAllNodes, nodeCatchAlls...)
-			if errCode != 0 {
-				errCode = matchErrCode
-			}
-		}
+// rootsForMethod - returns the roots that the path is matched against (in order).
+// With FallbackToCatchAll only the routes registered for the method are considered,
+// followed by the GET routes for HEAD requests and all routes for OPTIONS requests
+// (if they are handled automatically). Roots are nil if there are no routes
+func (m *Matcher) rootsForMethod(index *routeIndex, method string) [3]*radixNode {
+	if !m.FallbackToCatchAll {
+		return [3]*radixNode{index.root}
+	}
+	roots := [3]*radixNode{index.methodRoots[method]}
+	if method == http.MethodHead && m.router.autoHead {
+		roots[1] = index.methodRoots[http.MethodGet]
+	} else if method == http.MethodOptions && m.router.autoOptions {
+		roots[1] = index.root
+	}
+	if roots[0] == nil {
+		// routes registered for all methods
+		roots[2] = index.methodRoots[MethodAny]
 	}
-	return matchedNodes, catchAllNodes, errCode
+	return roots
 }
 
-func (m *Matcher) checkNodesForMatches(method string, candidate MatchCandidate, nodes []*Node, parentMatch *Match) (matches []*Match, catchalls []*Match, errorCode int) {
-	matchedNodes := []*Match{}
-	catchAllNodes := []*Match{}
-	errCode := 0
-
-	for _, node := range nodes {
-		isWildcard := isWildcardPart(node.part)
-		if (node.nodeType == ComponentTypeFixed && strings.ToLower(node.part) == strings.ToLower(candidate.part)) ||
-			isWildcard {
-			match := NewMatchWithParent(node, parentMatch)
-			if isWildcard {
-				paramKey := strings.ToLower(node.part[1:len(node.part)])
-				arr := match.Params[paramKey]
-				if arr == nil {
-					arr = []string{}
+// matchRoot - matches the path against the routes below the root, falling back to
+// the deepest catch-all that matched (if any)
+func (match *Match) matchRoot(root *radixNode) {
+	if match.path == "" {
+		if root.hasRoutes {
+			match.Node = root.source
+		} else {
+			for _, catchAll := range root.catchAlls {
+				if catchAll.matchesEmpty {
+					match.recordCatchAll(catchAll, 0)
+					break
 				}
-				arr = append(arr, candidate.part)
-				match.Params[paramKey] = arr
-			}
-			matchedNodes = append(matchedNodes, match)
-			if m.FallbackToCatchAll == false && candidate.HasRemainingCandidates() == false {
-				break // break early, we found a match
 			}
-		} else if isCatchAllPart(node.part) {
-			match := NewMatchWithParent(node, parentMatch)
-			match.CatchAllValue = candidate.currentPath
-			catchAllNodes = append(catchAllNodes, match)
+		}
+	} else if found := match.walk(root, 0); found != nil && found.kind != ComponentTypeCatchAll {
+		match.Node = found.source
+	}
+	if match.Node == nil && match.catchAllNode != nil {
+		match.Node = match.catchAllNode.source
+		match.CatchAllValue = match.fullPath[match.catchAllPos:]
+		match.params, match.catchAllParams = match.catchAllParams, match.params
+		if match.catchAllNode.paramKey != "" {
+			match.params = append(match.params, matchParam{
+				node:  match.Node,
+				key:   match.catchAllNode.paramKey,
+				value: match.CatchAllValue,
+			})
 		}
 	}
-
-	return matchedNodes, catchAllNodes, errCode
-}
-
-// MatchCandidate is a helper class for matching path components
-type MatchCandidate struct {
-	part        string
-	remainder   string
-	currentPath string
-}
-
-// NewMatchCandidate creates a new match candidate instance and initializes if for the first part
-func NewMatchCandidate(path string) MatchCandidate {
-	cleanPath := path
-	if strings.HasPrefix(path, "/") {
-		cleanPath = path[1:len(path)]
-	}
-	split := strings.SplitN(cleanPath, "/", 2)
-	candidate := MatchCandidate{
-		part:        split[0],
-		remainder:   "",
-		currentPath: cleanPath,
-	}
-	if len(split) == 2 {
-		candidate.remainder = split[1]
-	}
-	return candidate
-}
-
-// NextCandidate returns the next MatchCandidate in the full path
-func (mc MatchCandidate) NextCandidate() MatchCandidate {
-	if mc.remainder == "" {
-		return NoMatchCandidate()
-	}
-	return NewMatchCandidate(mc.remainder)
 }
 
-// HasRemainingCandidates returns true if the MatchCandidate has more candidate parts
-func (mc MatchCandidate) HasRemainingCandidates() bool {
-	return mc.remainder != ""
-}
-
-// NoMatchCandidate represents an empty MatchCandidate
-func NoMatchCandidate() MatchCandidate {
-	return MatchCandidate{
-		part:      "",
-		remainder: "",
+// ReleaseMatch returns a Match to the pool. The Match must not be used afterwards.
+func (m *Matcher) ReleaseMatch(match *Match) {
+	if match == nil {
+		return
 	}
-}
-
-// IsNoMatch returns true if the MatchCandidate equals the NoMatchCandidate value
-func (mc MatchCandidate) IsNoMatch() bool {
-	return mc == NoMatchCandidate()
+	match.Node = nil
+	match.catchAllNode = nil
+	m.matchPool.Put(match)
 }
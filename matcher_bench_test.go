@@ -0,0 +1,502 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro
+
+import (
+	"strings"
+	"testing"
+)
+
+// githubAPI - the GitHub v3 API route table
+var githubAPI = []struct {
+	method string
+	path   string
+}{
+	// OAuth Authorizations
+	{"GET", "/authorizations"},
+	{"GET", "/authorizations/:id"},
+	{"POST", "/authorizations"},
+	{"PUT", "/authorizations/clients/:client_id"},
+	{"PATCH", "/authorizations/:id"},
+	{"DELETE", "/authorizations/:id"},
+	{"GET", "/applications/:client_id/tokens/:access_token"},
+	{"DELETE", "/applications/:client_id/tokens"},
+	{"DELETE", "/applications/:client_id/tokens/:access_token"},
+	// Activity
+	{"GET", "/events"},
+	{"GET", "/repos/:owner/:repo/events"},
+	{"GET", "/networks/:owner/:repo/events"},
+	{"GET", "/orgs/:org/events"},
+	{"GET", "/users/:user/received_events"},
+	{"GET", "/users/:user/received_events/public"},
+	{"GET", "/users/:user/events"},
+	{"GET", "/users/:user/events/public"},
+	{"GET", "/users/:user/events/orgs/:org"},
+	{"GET", "/feeds"},
+	{"GET", "/notifications"},
+	{"GET", "/repos/:owner/:repo/notifications"},
+	{"PUT", "/notifications"},
+	{"PUT", "/repos/:owner/:repo/notifications"},
+	{"GET", "/notifications/threads/:id"},
+	{"PATCH", "/notifications/threads/:id"},
+	{"GET", "/notifications/threads/:id/subscription"},
+	{"PUT", "/notifications/threads/:id/subscription"},
+	{"DELETE", "/notifications/threads/:id/subscription"},
+	{"GET", "/repos/:owner/:repo/stargazers"},
+	{"GET", "/users/:user/starred"},
+	{"GET", "/user/starred"},
+	{"GET", "/user/starred/:owner/:repo"},
+	{"PUT", "/user/starred/:owner/:repo"},
+	{"DELETE", "/user/starred/:owner/:repo"},
+	{"GET", "/repos/:owner/:repo/subscribers"},
+	{"GET", "/users/:user/subscriptions"},
+	{"GET", "/user/subscriptions"},
+	{"GET", "/repos/:owner/:repo/subscription"},
+	{"PUT", "/repos/:owner/:repo/subscription"},
+	{"DELETE", "/repos/:owner/:repo/subscription"},
+	{"GET", "/user/subscriptions/:owner/:repo"},
+	{"PUT", "/user/subscriptions/:owner/:repo"},
+	{"DELETE", "/user/subscriptions/:owner/:repo"},
+	// Gists
+	{"GET", "/users/:user/gists"},
+	{"GET", "/gists"},
+	{"GET", "/gists/public"},
+	{"GET", "/gists/starred"},
+	{"GET", "/gists/:id"},
+	{"POST", "/gists"},
+	{"PATCH", "/gists/:id"},
+	{"PUT", "/gists/:id/star"},
+	{"DELETE", "/gists/:id/star"},
+	{"GET", "/gists/:id/star"},
+	{"POST", "/gists/:id/forks"},
+	{"DELETE", "/gists/:id"},
+	// Git Data
+	{"GET", "/repos/:owner/:repo/git/blobs/:sha"},
+	{"POST", "/repos/:owner/:repo/git/blobs"},
+	{"GET", "/repos/:owner/:repo/git/commits/:sha"},
+	{"POST", "/repos/:owner/:repo/git/commits"},
+	{"GET", "/repos/:owner/:repo/git/refs/*"},
+	{"GET", "/repos/:owner/:repo/git/refs"},
+	{"POST", "/repos/:owner/:repo/git/refs"},
+	{"PATCH", "/repos/:owner/:repo/git/refs/*"},
+	{"DELETE", "/repos/:owner/:repo/git/refs/*"},
+	{"GET", "/repos/:owner/:repo/git/tags/:sha"},
+	{"POST", "/repos/:owner/:repo/git/tags"},
+	{"GET", "/repos/:owner/:repo/git/trees/:sha"},
+	{"POST", "/repos/:owner/:repo/git/trees"},
+	// Issues
+	{"GET", "/issues"},
+	{"GET", "/user/issues"},
+	{"GET", "/orgs/:org/issues"},
+	{"GET", "/repos/:owner/:repo/issues"},
+	{"GET", "/repos/:owner/:repo/issues/:number"},
+	{"POST", "/repos/:owner/:repo/issues"},
+	{"PATCH", "/repos/:owner/:repo/issues/:number"},
+	{"GET", "/repos/:owner/:repo/assignees"},
+	{"GET", "/repos/:owner/:repo/assignees/:assignee"},
+	{"GET", "/repos/:owner/:repo/issues/:number/comments"},
+	{"POST", "/repos/:owner/:repo/issues/:number/comments"},
+	{"GET", "/repos/:owner/:repo/issues/:number/events"},
+	{"GET", "/repos/:owner/:repo/labels"},
+	{"GET", "/repos/:owner/:repo/labels/:name"},
+	{"POST", "/repos/:owner/:repo/labels"},
+	{"PATCH", "/repos/:owner/:repo/labels/:name"},
+	{"DELETE", "/repos/:owner/:repo/labels/:name"},
+	{"GET", "/repos/:owner/:repo/issues/:number/labels"},
+	{"POST", "/repos/:owner/:repo/issues/:number/labels"},
+	{"DELETE", "/repos/:owner/:repo/issues/:number/labels/:name"},
+	{"PUT", "/repos/:owner/:repo/issues/:number/labels"},
+	{"DELETE", "/repos/:owner/:repo/issues/:number/labels"},
+	{"GET", "/repos/:owner/:repo/milestones/:number/labels"},
+	{"GET", "/repos/:owner/:repo/milestones"},
+	{"GET", "/repos/:owner/:repo/milestones/:number"},
+	{"POST", "/repos/:owner/:repo/milestones"},
+	{"PATCH", "/repos/:owner/:repo/milestones/:number"},
+	{"DELETE", "/repos/:owner/:repo/milestones/:number"},
+	// Miscellaneous
+	{"GET", "/emojis"},
+	{"GET", "/gitignore/templates"},
+	{"GET", "/gitignore/templates/:name"},
+	{"POST", "/markdown"},
+	{"POST", "/markdown/raw"},
+	{"GET", "/meta"},
+	{"GET", "/rate_limit"},
+	// Organizations
+	{"GET", "/users/:user/orgs"},
+	{"GET", "/user/orgs"},
+	{"GET", "/orgs/:org"},
+	{"PATCH", "/orgs/:org"},
+	{"GET", "/orgs/:org/members"},
+	{"GET", "/orgs/:org/members/:user"},
+	{"DELETE", "/orgs/:org/members/:user"},
+	{"GET", "/orgs/:org/public_members"},
+	{"GET", "/orgs/:org/public_members/:user"},
+	{"PUT", "/orgs/:org/public_members/:user"},
+	{"DELETE", "/orgs/:org/public_members/:user"},
+	{"GET", "/orgs/:org/teams"},
+	{"GET", "/teams/:id"},
+	{"POST", "/orgs/:org/teams"},
+	{"PATCH", "/teams/:id"},
+	{"DELETE", "/teams/:id"},
+	{"GET", "/teams/:id/members"},
+	{"GET", "/teams/:id/members/:user"},
+	{"PUT", "/teams/:id/members/:user"},
+	{"DELETE", "/teams/:id/members/:user"},
+	{"GET", "/teams/:id/repos"},
+	{"GET", "/teams/:id/repos/:owner/:repo"},
+	{"PUT", "/teams/:id/repos/:owner/:repo"},
+	{"DELETE", "/teams/:id/repos/:owner/:repo"},
+	{"GET", "/user/teams"},
+	// Pull Requests
+	{"GET", "/repos/:owner/:repo/pulls"},
+	{"GET", "/repos/:owner/:repo/pulls/:number"},
+	{"POST", "/repos/:owner/:repo/pulls"},
+	{"PATCH", "/repos/:owner/:repo/pulls/:number"},
+	{"GET", "/repos/:owner/:repo/pulls/:number/commits"},
+	{"GET", "/repos/:owner/:repo/pulls/:number/files"},
+	{"GET", "/repos/:owner/:repo/pulls/:number/merge"},
+	{"PUT", "/repos/:owner/:repo/pulls/:number/merge"},
+	{"GET", "/repos/:owner/:repo/pulls/:number/comments"},
+	{"PUT", "/repos/:owner/:repo/pulls/:number/comments"},
+	// Repositories
+	{"GET", "/user/repos"},
+	{"GET", "/users/:user/repos"},
+	{"GET", "/orgs/:org/repos"},
+	{"GET", "/repositories"},
+	{"POST", "/user/repos"},
+	{"POST", "/orgs/:org/repos"},
+	{"GET", "/repos/:owner/:repo"},
+	{"PATCH", "/repos/:owner/:repo"},
+	{"GET", "/repos/:owner/:repo/contributors"},
+	{"GET", "/repos/:owner/:repo/languages"},
+	{"GET", "/repos/:owner/:repo/teams"},
+	{"GET", "/repos/:owner/:repo/tags"},
+	{"GET", "/repos/:owner/:repo/branches"},
+	{"GET", "/repos/:owner/:repo/branches/:branch"},
+	{"DELETE", "/repos/:owner/:repo"},
+	{"GET", "/repos/:owner/:repo/collaborators"},
+	{"GET", "/repos/:owner/:repo/collaborators/:user"},
+	{"PUT", "/repos/:owner/:repo/collaborators/:user"},
+	{"DELETE", "/repos/:owner/:repo/collaborators/:user"},
+	{"GET", "/repos/:owner/:repo/comments"},
+	{"GET", "/repos/:owner/:repo/commits/:sha/comments"},
+	{"POST", "/repos/:owner/:repo/commits/:sha/comments"},
+	{"GET", "/repos/:owner/:repo/comments/:id"},
+	{"PATCH", "/repos/:owner/:repo/comments/:id"},
+	{"DELETE", "/repos/:owner/:repo/comments/:id"},
+	{"GET", "/repos/:owner/:repo/commits"},
+	{"GET", "/repos/:owner/:repo/commits/:sha"},
+	{"GET", "/repos/:owner/:repo/readme"},
+	{"GET", "/repos/:owner/:repo/contents/*"},
+	{"PUT", "/repos/:owner/:repo/contents/*"},
+	{"DELETE", "/repos/:owner/:repo/contents/*"},
+	{"GET", "/repos/:owner/:repo/keys"},
+	{"GET", "/repos/:owner/:repo/keys/:id"},
+	{"POST", "/repos/:owner/:repo/keys"},
+	{"PATCH", "/repos/:owner/:repo/keys/:id"},
+	{"DELETE", "/repos/:owner/:repo/keys/:id"},
+	{"GET", "/repos/:owner/:repo/downloads"},
+	{"GET", "/repos/:owner/:repo/downloads/:id"},
+	{"DELETE", "/repos/:owner/:repo/downloads/:id"},
+	{"GET", "/repos/:owner/:repo/forks"},
+	{"POST", "/repos/:owner/:repo/forks"},
+	{"GET", "/repos/:owner/:repo/hooks"},
+	{"GET", "/repos/:owner/:repo/hooks/:id"},
+	{"POST", "/repos/:owner/:repo/hooks"},
+	{"PATCH", "/repos/:owner/:repo/hooks/:id"},
+	{"POST", "/repos/:owner/:repo/hooks/:id/tests"},
+	{"DELETE", "/repos/:owner/:repo/hooks/:id"},
+	{"POST", "/repos/:owner/:repo/merges"},
+	{"GET", "/repos/:owner/:repo/releases"},
+	{"GET", "/repos/:owner/:repo/releases/:id"},
+	{"POST", "/repos/:owner/:repo/releases"},
+	{"PATCH", "/repos/:owner/:repo/releases/:id"},
+	{"DELETE", "/repos/:owner/:repo/releases/:id"},
+	{"GET", "/repos/:owner/:repo/releases/:id/assets"},
+	{"GET", "/repos/:owner/:repo/stats/contributors"},
+	{"GET", "/repos/:owner/:repo/stats/commit_activity"},
+	{"GET", "/repos/:owner/:repo/stats/code_frequency"},
+	{"GET", "/repos/:owner/:repo/stats/participation"},
+	{"GET", "/repos/:owner/:repo/stats/punch_card"},
+	{"GET", "/repos/:owner/:repo/statuses/:ref"},
+	{"POST", "/repos/:owner/:repo/statuses/:ref"},
+	// Search
+	{"GET", "/search/repositories"},
+	{"GET", "/search/code"},
+	{"GET", "/search/issues"},
+	{"GET", "/search/users"},
+	{"GET", "/legacy/issues/search/:owner/:repository/:state/:keyword"},
+	{"GET", "/legacy/repos/search/:keyword"},
+	{"GET", "/legacy/user/search/:keyword"},
+	{"GET", "/legacy/user/email/:email"},
+	// Users
+	{"GET", "/users/:user"},
+	{"GET", "/user"},
+	{"PATCH", "/user"},
+	{"GET", "/users"},
+	{"GET", "/user/emails"},
+	{"POST", "/user/emails"},
+	{"DELETE", "/user/emails"},
+	{"GET", "/users/:user/followers"},
+	{"GET", "/user/followers"},
+	{"GET", "/users/:user/following"},
+	{"GET", "/user/following"},
+	{"GET", "/user/following/:user"},
+	{"GET", "/users/:user/following/:target_user"},
+	{"PUT", "/user/following/:user"},
+	{"DELETE", "/user/following/:user"},
+	{"GET", "/users/:user/keys"},
+	{"GET", "/user/keys"},
+	{"GET", "/user/keys/:id"},
+	{"POST", "/user/keys"},
+	{"PATCH", "/user/keys/:id"},
+	{"DELETE", "/user/keys/:id"},
+}
+
+// githubRequestPath - converts a route path into a request path by filling in
+// the wildcards and catch-alls
+func githubRequestPath(routePath string) string {
+	parts := strings.Split(routePath, "/")
+	for idx, part := range parts {
+		if isWildcardPart(part) {
+			parts[idx] = "goro" + part[1:]
+		} else if isCatchAllPart(part) {
+			parts[idx] = "some/file.txt"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func loadGitHubRouter() *Router {
+	githubRouter := NewRouter()
+	for _, route := range githubAPI {
+		githubRouter.Add(route.method, route.path).HandleFunc(func(ctx *HandlerContext) {})
+	}
+	return githubRouter
+}
+
+func TestMatcherGitHubAPI(t *testing.T) {
+	githubRouter := loadGitHubRouter()
+	for _, fallback := range []bool{false, true} {
+		matcher := githubRouter.NewMatcher()
+		matcher.FallbackToCatchAll = fallback
+		for _, route := range githubAPI {
+			requestPath := githubRequestPath(route.path)
+			match := matcher.MatchPathToRoute(route.method, requestPath, nil)
+			if match == nil {
+				t.Error("Expected a match for", route.method, requestPath)
+				continue
+			}
+			matchedRoute := match.Node.RouteForMethod(route.method)
+			if matchedRoute == nil || matchedRoute.PathFormat != route.path {
+				t.Error("Expected", requestPath, "to match", route.path, "but got", matchedRoute)
+			}
+			matcher.ReleaseMatch(match)
+		}
+	}
+}
+
+func TestMatcherZeroAllocs(t *testing.T) {
+	githubRouter := loadGitHubRouter()
+	matcher := githubRouter.routeMatcher
+	requestPath := "/repos/theyakka/goro/issues/42/comments"
+	// warm up the route index and the match pool
+	matcher.ReleaseMatch(matcher.MatchPathToRoute("GET", requestPath, nil))
+	allocs := testing.AllocsPerRun(100, func() {
+		matcher.ReleaseMatch(matcher.MatchPathToRoute("GET", requestPath, nil))
+	})
+	if allocs > 0 {
+		t.Error("Expected matching to be allocation free but got", allocs, "allocations")
+	}
+}
+
+func benchmarkMatcher(b *testing.B, method string, requestPath string) {
+	matcher := loadGitHubRouter().routeMatcher
+	// warm up the route index and the match pool
+	matcher.ReleaseMatch(matcher.MatchPathToRoute(method, requestPath, nil))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		matcher.ReleaseMatch(matcher.MatchPathToRoute(method, requestPath, nil))
+	}
+}
+
+func benchmarkLegacyMatcher(b *testing.B, method string, requestPath string) {
+	matcher := newLegacyMatcher(loadGitHubRouter().Tree())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		matcher.match(method, requestPath)
+	}
+}
+
+func BenchmarkMatcher_GitHubStatic(b *testing.B) {
+	benchmarkMatcher(b, "GET", "/user/repos")
+}
+
+func BenchmarkLegacyMatcher_GitHubStatic(b *testing.B) {
+	benchmarkLegacyMatcher(b, "GET", "/user/repos")
+}
+
+func BenchmarkMatcher_GitHubParam(b *testing.B) {
+	benchmarkMatcher(b, "GET", "/repos/theyakka/goro/issues/42/comments")
+}
+
+func BenchmarkLegacyMatcher_GitHubParam(b *testing.B) {
+	benchmarkLegacyMatcher(b, "GET", "/repos/theyakka/goro/issues/42/comments")
+}
+
+func BenchmarkMatcher_GitHubCatchAll(b *testing.B) {
+	benchmarkMatcher(b, "GET", "/repos/theyakka/goro/contents/docs/guide/index.md")
+}
+
+func BenchmarkLegacyMatcher_GitHubCatchAll(b *testing.B) {
+	benchmarkLegacyMatcher(b, "GET", "/repos/theyakka/goro/contents/docs/guide/index.md")
+}
+
+func BenchmarkMatcher_GitHubAll(b *testing.B) {
+	matcher := loadGitHubRouter().routeMatcher
+	requestPaths := make([]string, len(githubAPI))
+	for idx, route := range githubAPI {
+		requestPaths[idx] = githubRequestPath(route.path)
+	}
+	matcher.ReleaseMatch(matcher.MatchPathToRoute("GET", requestPaths[0], nil))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for idx, route := range githubAPI {
+			matcher.ReleaseMatch(matcher.MatchPathToRoute(route.method, requestPaths[idx], nil))
+		}
+	}
+}
+
+func BenchmarkLegacyMatcher_GitHubAll(b *testing.B) {
+	matcher := newLegacyMatcher(loadGitHubRouter().Tree())
+	requestPaths := make([]string, len(githubAPI))
+	for idx, route := range githubAPI {
+		requestPaths[idx] = githubRequestPath(route.path)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for idx, route := range githubAPI {
+			matcher.match(route.method, requestPaths[idx])
+		}
+	}
+}
+
+// legacyMatcher - the original segment-by-segment matcher. It is kept here so that
+// the compiled matcher can be benchmarked against it.
+type legacyMatcher struct {
+	tree *Tree
+}
+
+type legacyMatch struct {
+	node          *Node
+	params        map[string][]interface{}
+	catchAllValue string
+}
+
+type legacyCandidate struct {
+	part        string
+	remainder   string
+	currentPath string
+}
+
+func newLegacyMatcher(tree *Tree) *legacyMatcher {
+	return &legacyMatcher{tree: tree}
+}
+
+func newLegacyMatch(node *Node, parent *legacyMatch) *legacyMatch {
+	match := &legacyMatch{node: node, params: map[string][]interface{}{}}
+	if parent != nil {
+		for key, value := range parent.params {
+			match.params[key] = value
+		}
+		match.catchAllValue = parent.catchAllValue
+	}
+	return match
+}
+
+func newLegacyCandidate(path string) legacyCandidate {
+	cleanPath := strings.TrimPrefix(path, "/")
+	split := strings.SplitN(cleanPath, "/", 2)
+	candidate := legacyCandidate{part: split[0], currentPath: cleanPath}
+	if len(split) == 2 {
+		candidate.remainder = split[1]
+	}
+	return candidate
+}
+
+func (lm *legacyMatcher) match(method string, path string) *legacyMatch {
+	var currentMatches []*legacyMatch
+	candidate := newLegacyCandidate(path)
+	if path == RootPath {
+		candidate.part = RootPath
+	}
+	var finalMatches, catchAlls []*legacyMatch
+	for candidate != (legacyCandidate{}) {
+		var matches, catchAllMatches []*legacyMatch
+		if currentMatches == nil {
+			matches, catchAllMatches = lm.check(candidate, lm.tree.nodes, nil)
+		} else {
+			for _, match := range currentMatches {
+				nodeMatches, nodeCatchAlls := lm.check(candidate, match.node.nodes, match)
+				matches = append(matches, nodeMatches...)
+				catchAllMatches = append(catchAllMatches, nodeCatchAlls...)
+			}
+		}
+		catchAlls = append(catchAllMatches, catchAlls...)
+		if len(matches) == 0 {
+			break
+		}
+		currentMatches = matches
+		if candidate.remainder == "" {
+			finalMatches = append(finalMatches, matches...)
+			break
+		}
+		candidate = newLegacyCandidate(candidate.remainder)
+	}
+	if len(finalMatches) > 0 {
+		return finalMatches[0]
+	}
+	if len(catchAlls) > 0 {
+		return catchAlls[0]
+	}
+	return nil
+}
+
+func (lm *legacyMatcher) check(candidate legacyCandidate, nodes []*Node, parent *legacyMatch) (matches []*legacyMatch, catchAlls []*legacyMatch) {
+	for _, node := range nodes {
+		isWildcard := isWildcardPart(node.part)
+		var paramValue interface{}
+		if isWildcard {
+			converted, ok := node.ParamValue(candidate.part)
+			if !ok {
+				continue
+			}
+			paramValue = converted
+		}
+		if (node.nodeType == ComponentTypeFixed && strings.ToLower(node.part) == strings.ToLower(candidate.part)) ||
+			isWildcard {
+			match := newLegacyMatch(node, parent)
+			if isWildcard {
+				paramKey := strings.ToLower(node.paramName)
+				match.params[paramKey] = append(match.params[paramKey], paramValue)
+			}
+			matches = append(matches, match)
+		} else if isCatchAllPart(node.part) {
+			match := newLegacyMatch(node, parent)
+			match.catchAllValue = candidate.currentPath
+			catchAlls = append(catchAlls, match)
+		}
+	}
+	return matches, catchAlls
+}
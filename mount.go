@@ -0,0 +1,94 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// mountHandler - dispatches requests to a mounted http.Handler with the mount
+// prefix removed from the request path
+type mountHandler struct {
+	handler http.Handler
+}
+
+// Mount attaches an http.Handler to the prefix. All requests for the prefix, or
+// any path below it, are sent to the handler (for all methods) with the prefix
+// removed from Request.URL.Path. The original path is available using
+// HandlerContext.OriginalPath. If the handler is a Router, it shares the request
+// context so that the filters of this Router are executed first and the error
+// handlers of this Router are used if the mounted Router doesn't define them.
+func (r *Router) Mount(prefix string, handler http.Handler) *Route {
+	if childRouter, ok := handler.(*Router); ok {
+		childRouter.parent = r
+	}
+	return r.Use(newMountRoute(prefix, handler))[0]
+}
+
+// Mount attaches an http.Handler to the prefix (within the group). See Router.Mount
+func (g *Group) Mount(prefix string, handler http.Handler) *Route {
+	if childRouter, ok := handler.(*Router); ok {
+		childRouter.parent = g.router
+	}
+	route := newMountRoute(path.Join(g.prefix, prefix), handler)
+	route.namePrefix = g.FullName()
+	route.group = g
+	return g.router.Use(route)[0]
+}
+
+// newMountRoute - creates a catch-all route for all methods that will dispatch to
+// the handler
+func newMountRoute(prefix string, handler http.Handler) *Route {
+	route := NewRoute(MethodAny, path.Join("/", prefix, "*"))
+	route.mount = handler
+	route.Handler = mountHandler{handler: handler}
+	return route
+}
+
+// Serve - implement the ContextHandler interface
+func (mh mountHandler) Serve(ctx *HandlerContext) {
+	if childRouter, isRouter := mh.handler.(*Router); isRouter {
+		serveMountedRouter(ctx, childRouter, "/"+ctx.CatchAllValue)
+		return
+	}
+	mh.handler.ServeHTTP(ctx.ResponseWriter, requestWithPath(ctx.Request, "/"+ctx.CatchAllValue))
+}
+
+// serveMountedRouter - dispatches the request to a mounted router using the path.
+// the mounted router uses the same context so the state that it changes is saved
+// and restored so that the post filters for the parent router execute as normal.
+// the prefix that was removed is kept so that redirects include it
+func serveMountedRouter(ctx *HandlerContext, childRouter *Router, requestPath string) {
+	savedRequest, savedPath, savedCatchAll := ctx.Request, ctx.Path, ctx.CatchAllValue
+	savedParams, savedRoute, savedRouter := ctx.Parameters, ctx.route, ctx.router
+	savedMountPrefix := ctx.mountPrefix
+	ctx.mountPrefix += strings.TrimSuffix(strings.TrimSuffix(ctx.Path, ctx.CatchAllValue), "/")
+	ctx.Request = requestWithPath(savedRequest, requestPath)
+	ctx.router = childRouter
+	ctx.route = nil
+	childRouter.serveContext(ctx)
+	delete(ctx.internalState, StateKeyHasExecutedPostFilters)
+	ctx.Request, ctx.Path, ctx.CatchAllValue = savedRequest, savedPath, savedCatchAll
+	ctx.Parameters, ctx.route, ctx.router = savedParams, savedRoute, savedRouter
+	ctx.mountPrefix = savedMountPrefix
+}
+
+// requestWithPath - returns a shallow copy of the request with a different path
+func requestWithPath(req *http.Request, requestPath string) *http.Request {
+	pathRequest := new(http.Request)
+	*pathRequest = *req
+	pathURL := *req.URL
+	pathURL.Path = requestPath
+	pathURL.RawPath = ""
+	pathRequest.URL = &pathURL
+	return pathRequest
+}
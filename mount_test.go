@@ -0,0 +1,86 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/theyakka/goro"
+)
+
+func TestMountHandler(t *testing.T) {
+	var servedPath string
+	mountRouter := goro.NewRouter()
+	mountRouter.GET("/debug/status").HandleFunc(testHandler)
+	mountRouter.Mount("/debug", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		servedPath = req.URL.Path + "?" + req.URL.RawQuery
+	}))
+	mountRouter.Group("/admin").Mount("/ui", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		servedPath = req.Method + " " + req.URL.Path
+	}))
+
+	expectations := map[string]string{
+		"GET /debug/pprof/heap?seconds=1": "/pprof/heap?seconds=1",
+		"GET /debug":                      "/?",
+		"GET /debug/":                     "/?",
+		"DELETE /admin/ui/users/1":        "DELETE /users/1",
+	}
+	for request, expected := range expectations {
+		servedPath = ""
+		requestParts := strings.SplitN(request, " ", 2)
+		execMockRequest(mountRouter, requestParts[0], requestParts[1])
+		if servedPath != expected {
+			t.Error("Expected", request, "to be served as", expected, "but got", servedPath)
+		}
+	}
+	// routes registered on the router take precedence
+	servedPath = ""
+	expectHitResult(t, mountRouter, "GET", "/debug/status")
+	if servedPath != "" {
+		t.Error("Expected the mounted handler to not be called but got", servedPath)
+	}
+}
+
+func TestMountRouter(t *testing.T) {
+	var order []string
+	var originalPath string
+	parentRouter := goro.NewRouter()
+	parentRouter.AddFilter(orderFilter{name: "parent", order: &order})
+	parentRouter.SetErrorHandler(http.StatusNotFound, goro.ContextHandlerFunc(func(ctx *goro.HandlerContext) {
+		order = append(order, "parent-404")
+		ctx.ResponseWriter.WriteHeader(http.StatusNotFound)
+	}))
+	childRouter := goro.NewRouter()
+	childRouter.AddFilter(orderFilter{name: "child", order: &order})
+	childRouter.GET("/users/:id").HandleFunc(func(ctx *goro.HandlerContext) {
+		order = append(order, "handler:"+ctx.Parameters.GetFirstString("id"))
+		originalPath = ctx.OriginalPath
+	})
+	parentRouter.Mount("/api", childRouter)
+
+	execMockRequest(parentRouter, "GET", "/api/users/42")
+	if strings.Join(order, ",") != "parent,child,handler:42" {
+		t.Error("Expected parent,child,handler:42 but got", strings.Join(order, ","))
+	}
+	if originalPath != "/api/users/42" {
+		t.Error("Expected the original path to be /api/users/42 but got", originalPath)
+	}
+
+	order = nil
+	req, _ := http.NewRequest("GET", "/api/missing", nil)
+	w := httptest.NewRecorder()
+	parentRouter.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound || strings.Join(order, ",") != "parent,child,parent-404" {
+		t.Error("Expected the parent 404 handler to be used but got", w.Code, strings.Join(order, ","))
+	}
+}
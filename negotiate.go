@@ -0,0 +1,142 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrNotAcceptable - none of the available representations are acceptable to the
+// client (see HandlerContext.Negotiate)
+var ErrNotAcceptable = errors.New("none of the available representations are acceptable")
+
+// Negotiate renders data using the registered encoder (see Router.SetEncoder) that
+// best matches the request's Accept header and writes it with the status code. Ties
+// are resolved using the order the encoders were registered in and a missing Accept
+// header accepts any encoder. If no encoder is acceptable, a 406 (Not Acceptable)
+// error is emitted and ErrNotAcceptable is returned
+func (hc *HandlerContext) Negotiate(status int, data interface{}) error {
+	addVary(hc.ResponseWriter.Header(), "Accept")
+	var mediaTypes []string
+	if hc.router != nil {
+		mediaTypes = hc.router.negotiableTypes()
+	} else {
+		mediaTypes = []string{MIMEApplicationJSON, MIMEApplicationXML}
+	}
+	mediaType := negotiate(hc.Request.Header.Get("Accept"), mediaTypes, acceptQuality)
+	if mediaType == "" {
+		if hc.router != nil {
+			hc.router.emitError(hc, http.StatusNotAcceptable, "Not Acceptable",
+				RouterGenericErrorCode, ErrNotAcceptable)
+		}
+		return ErrNotAcceptable
+	}
+	return hc.Render(status, mediaType, data)
+}
+
+// NegotiateLanguage returns the language (e.g.: 'en-US') that best matches the
+// request's Accept-Language header. A language range matches the languages it is a
+// prefix of (e.g.: 'en' matches 'en-US'). Returns an empty string if none of the
+// languages are acceptable
+func (hc *HandlerContext) NegotiateLanguage(languages ...string) string {
+	addVary(hc.ResponseWriter.Header(), "Accept-Language")
+	return negotiate(hc.Request.Header.Get("Accept-Language"), languages, languageQuality)
+}
+
+// NegotiateEncoding returns the content coding (e.g.: 'gzip') that best matches the
+// request's Accept-Encoding header. 'identity' is acceptable unless it is explicitly
+// excluded. Returns an empty string if none of the encodings are acceptable
+func (hc *HandlerContext) NegotiateEncoding(encodings ...string) string {
+	addVary(hc.ResponseWriter.Header(), "Accept-Encoding")
+	return negotiate(hc.Request.Header.Get("Accept-Encoding"), encodings, encodingQuality)
+}
+
+// negotiableTypes - returns the media types of the encoders registered with the
+// router and its parent routers, in order of preference
+func (r *Router) negotiableTypes() []string {
+	var mediaTypes []string
+	for router := r; router != nil; router = router.parent {
+		for _, mediaType := range router.encoderTypes {
+			if !containsString(mediaTypes, mediaType) {
+				mediaTypes = append(mediaTypes, mediaType)
+			}
+		}
+	}
+	return mediaTypes
+}
+
+// negotiate - returns the offer with the highest quality (using the quality function)
+// for the header. ties are resolved using the order of the offers. if the header is
+// empty the first offer is returned. returns an empty string if no offer is acceptable
+func negotiate(header string, offers []string,
+	quality func(entries []AcceptEntry, value string) float64) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	if strings.TrimSpace(header) == "" {
+		return offers[0]
+	}
+	entries := ParseAccept(header)
+	best := ""
+	bestQuality := 0.0
+	for _, offer := range offers {
+		if offerQuality := quality(entries, offer); offerQuality > bestQuality {
+			best = offer
+			bestQuality = offerQuality
+		}
+	}
+	return best
+}
+
+// languageQuality - returns the quality the header assigns to the language. the
+// longest matching language range is used. returns -1 if no range matches
+func languageQuality(entries []AcceptEntry, language string) float64 {
+	language = strings.ToLower(language)
+	quality := -1.0
+	matchLength := -1
+	for _, entry := range entries {
+		length := len(entry.Value)
+		if entry.Value == "*" {
+			length = 0
+		} else if entry.Value != language && !strings.HasPrefix(language, entry.Value+"-") {
+			continue
+		}
+		if length > matchLength {
+			quality = entry.Quality
+			matchLength = length
+		}
+	}
+	return quality
+}
+
+// encodingQuality - returns the quality the header assigns to the content coding.
+// 'identity' is acceptable (with the lowest non-zero quality) if no entry matches
+func encodingQuality(entries []AcceptEntry, encoding string) float64 {
+	quality := acceptQuality(entries, encoding)
+	if quality < 0 && strings.ToLower(encoding) == "identity" {
+		return 0.001
+	}
+	return quality
+}
+
+// addVary - adds the header name to the Vary header (if it is not already listed)
+func addVary(header http.Header, name string) {
+	for _, value := range header["Vary"] {
+		for _, existing := range strings.Split(value, ",") {
+			existing = strings.TrimSpace(existing)
+			if existing == "*" || strings.EqualFold(existing, name) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", name)
+}
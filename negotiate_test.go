@@ -0,0 +1,96 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/theyakka/goro"
+)
+
+func TestNegotiate(t *testing.T) {
+	var negotiateErr error
+	var language, encoding string
+	negotiateRouter := goro.NewRouter()
+	negotiateRouter.SetEncoder("text/csv", goro.EncoderFunc(func(w io.Writer, v interface{}) error {
+		_, writeErr := fmt.Fprintf(w, "name\n%s\n", v.(renderedUser).Name)
+		return writeErr
+	}))
+	negotiateRouter.GET("/user").HandleFunc(func(ctx *goro.HandlerContext) {
+		negotiateErr = ctx.Negotiate(http.StatusOK, renderedUser{Name: "bob"})
+	})
+	negotiateRouter.GET("/greeting").HandleFunc(func(ctx *goro.HandlerContext) {
+		language = ctx.NegotiateLanguage("en-US", "fr", "de")
+		encoding = ctx.NegotiateEncoding("gzip", "identity")
+	})
+
+	expectations := []struct {
+		accept      string
+		status      int
+		contentType string
+	}{
+		{"", http.StatusOK, "application/json; charset=utf-8"},
+		{"*/*", http.StatusOK, "application/json; charset=utf-8"},
+		{"text/csv", http.StatusOK, "text/csv"},
+		{"application/json;q=0.5, application/xml", http.StatusOK, "application/xml; charset=utf-8"},
+		{"text/*, application/json;q=0.9", http.StatusOK, "text/csv"},
+		{"*/*;q=0.8, application/json;q=0", http.StatusOK, "application/xml; charset=utf-8"},
+		{"image/png", http.StatusNotAcceptable, ""},
+	}
+	for _, expected := range expectations {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/user", nil)
+		if expected.accept != "" {
+			req.Header.Set("Accept", expected.accept)
+		}
+		negotiateRouter.ServeHTTP(recorder, req)
+		if recorder.Code != expected.status || recorder.Header().Get("Vary") != "Accept" {
+			t.Error("Unexpected response for Accept", expected.accept, recorder.Code, recorder.Header())
+		}
+		if expected.status == http.StatusNotAcceptable {
+			if negotiateErr != goro.ErrNotAcceptable {
+				t.Error("Expected ErrNotAcceptable but got", negotiateErr)
+			}
+			continue
+		}
+		if contentType := recorder.Header().Get("Content-Type"); contentType != expected.contentType {
+			t.Error("Expected", expected.contentType, "for Accept", expected.accept, "but got", contentType)
+		}
+	}
+
+	preferences := []struct {
+		acceptLanguage string
+		acceptEncoding string
+		language       string
+		encoding       string
+	}{
+		{"", "", "en-US", "gzip"},
+		{"fr-CA, fr;q=0.9, en;q=0.8", "gzip;q=0.5, identity", "fr", "identity"},
+		{"en", "br", "en-US", "identity"},
+		{"es, *;q=0.1, en-us;q=0", "identity;q=0, *;q=0", "fr", ""},
+	}
+	for _, expected := range preferences {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/greeting", nil)
+		req.Header.Set("Accept-Language", expected.acceptLanguage)
+		req.Header.Set("Accept-Encoding", expected.acceptEncoding)
+		negotiateRouter.ServeHTTP(recorder, req)
+		if language != expected.language || encoding != expected.encoding {
+			t.Error("Expected", expected.language, expected.encoding, "but got", language, encoding)
+		}
+		if vary := recorder.Header()["Vary"]; len(vary) != 2 {
+			t.Error("Expected Accept-Language and Accept-Encoding to be listed in Vary but got", vary)
+		}
+	}
+}
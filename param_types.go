@@ -0,0 +1,138 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro
+
+import (
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParamType - the type a wildcard parameter value will be validated against and
+// converted to when it is matched
+type ParamType int
+
+const (
+	// ParamTypeString - the default parameter type. no conversion takes place
+	ParamTypeString ParamType = 1 << iota
+	// ParamTypeInt - the value must be an integer and is stored as an int
+	ParamTypeInt
+	// ParamTypeFloat - the value must be a number and is stored as a float64
+	ParamTypeFloat
+	// ParamTypeUUID - the value must be a UUID and is stored as a UUID
+	ParamTypeUUID
+	// ParamTypeTime - the value must be an RFC3339 timestamp or unix time (in
+	// seconds) and is stored as a time.Time
+	ParamTypeTime
+)
+
+// paramTypeNames - maps the names used in route declarations (e.g.: ':id<int>')
+// to the parameter type
+var paramTypeNames = map[string]ParamType{
+	"":       ParamTypeString,
+	"string": ParamTypeString,
+	"int":    ParamTypeInt,
+	"float":  ParamTypeFloat,
+	"uuid":   ParamTypeUUID,
+	"time":   ParamTypeTime,
+}
+
+// ParamTypeForName - returns the ParamType for a type name used in a route
+// declaration. ok will be false if the type name is not known
+func ParamTypeForName(typeName string) (paramType ParamType, ok bool) {
+	paramType, ok = paramTypeNames[strings.ToLower(typeName)]
+	return paramType, ok
+}
+
+// Convert - validates the string value against the type and returns the converted
+// value. ok will be false if the value does not conform to the type
+func (pt ParamType) Convert(value string) (converted interface{}, ok bool) {
+	switch pt {
+	case ParamTypeInt:
+		intVal, convErr := strconv.Atoi(value)
+		return intVal, convErr == nil
+	case ParamTypeFloat:
+		floatVal, convErr := strconv.ParseFloat(value, 64)
+		return floatVal, convErr == nil
+	case ParamTypeUUID:
+		uuidVal, parseErr := ParseUUID(value)
+		return uuidVal, parseErr == nil
+	case ParamTypeTime:
+		timeVal, parseErr := parseTimeParam(value)
+		return timeVal, parseErr == nil
+	}
+	return value, true
+}
+
+// Accepts - returns true if the string value conforms to the type. Unlike Convert,
+// Accepts does not need to box the converted value so it can be used while matching
+func (pt ParamType) Accepts(value string) bool {
+	switch pt {
+	case ParamTypeInt:
+		_, convErr := strconv.Atoi(value)
+		return convErr == nil
+	case ParamTypeFloat:
+		_, convErr := strconv.ParseFloat(value, 64)
+		return convErr == nil
+	case ParamTypeUUID:
+		_, parseErr := ParseUUID(value)
+		return parseErr == nil
+	case ParamTypeTime:
+		_, parseErr := parseTimeParam(value)
+		return parseErr == nil
+	}
+	return true
+}
+
+// parseTimeParam - parses an RFC3339 timestamp or a unix timestamp (in seconds)
+func parseTimeParam(value string) (time.Time, error) {
+	if unixSeconds, convErr := strconv.ParseInt(value, 10, 64); convErr == nil {
+		return time.Unix(unixSeconds, 0).UTC(), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// UUID - a universally unique identifier (RFC 4122)
+type UUID [16]byte
+
+// ErrInvalidUUID - returned when a string cannot be parsed as a UUID
+var ErrInvalidUUID = errors.New("invalid UUID format")
+
+// ParseUUID - parses the canonical string representation of a UUID
+// (e.g.: 6ba7b810-9dad-11d1-80b4-00c04fd430c8)
+func ParseUUID(value string) (UUID, error) {
+	var uuid UUID
+	if len(value) != 36 || value[8] != '-' || value[13] != '-' ||
+		value[18] != '-' || value[23] != '-' {
+		return uuid, ErrInvalidUUID
+	}
+	hexString := value[0:8] + value[9:13] + value[14:18] + value[19:23] + value[24:]
+	if _, decodeErr := hex.Decode(uuid[:], []byte(hexString)); decodeErr != nil {
+		return UUID{}, ErrInvalidUUID
+	}
+	return uuid, nil
+}
+
+// String - the canonical string representation of the UUID
+func (u UUID) String() string {
+	buf := make([]byte, 36)
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf)
+}
@@ -9,10 +9,24 @@
 
 package goro
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
 type Parameters struct {
 	paramsMap map[string][]interface{}
 }
 
+// NewParameters - creates a new Parameters instance from (already converted) values
+func NewParameters(paramsMap map[string][]interface{}) *Parameters {
+	return &Parameters{
+		paramsMap: paramsMap,
+	}
+}
+
 func NewParametersWithMap(paramsMap map[string][]string) *Parameters {
 	interfaceMap := stringMapToInterfaceMap(paramsMap)
 	return &Parameters{
@@ -20,12 +34,44 @@ func NewParametersWithMap(paramsMap map[string][]string) *Parameters {
 	}
 }
 
+// values - returns the values for the key. Keys are stored in the case they were
+// declared in, however a case-insensitive match is used if there is no exact match
+func (p *Parameters) values(key string) []interface{} {
+	if values, ok := p.paramsMap[key]; ok {
+		return values
+	}
+	for paramKey, values := range p.paramsMap {
+		if strings.EqualFold(paramKey, key) {
+			return values
+		}
+	}
+	return nil
+}
+
+// Keys - returns the (sorted) parameter names, in the case they were declared in
+func (p *Parameters) Keys() []string {
+	keys := make([]string, 0, len(p.paramsMap))
+	for key := range p.paramsMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Get - returns a copy of the values for the key. Parameters can be shared between
+// requests (e.g.: when the matched route is cached) so the values are never exposed
 func (p *Parameters) Get(key string) []interface{} {
-	return p.paramsMap[key]
+	values := p.values(key)
+	if values == nil {
+		return nil
+	}
+	copied := make([]interface{}, len(values))
+	copy(copied, values)
+	return copied
 }
 
 func (p *Parameters) GetStrings(key string) []string {
-	val := p.paramsMap[key]
+	val := p.values(key)
 	if val == nil || len(val) == 0 {
 		return []string{}
 	}
@@ -33,6 +79,9 @@ func (p *Parameters) GetStrings(key string) []string {
 	for i, v := range val {
 		if n, ok := v.(string); ok {
 			outArr[i] = n
+		} else if v != nil {
+			// typed values are returned using their string representation
+			outArr[i] = fmt.Sprint(v)
 		}
 	}
 	return outArr
@@ -46,8 +95,23 @@ func (p *Parameters) GetFirstString(key string) string {
 	return strArr[0]
 }
 
+// GetSegments - returns the first value for the key split into path segments. Use
+// with named catch-alls (e.g.: '/files/+path') to get each matched segment
+func (p *Parameters) GetSegments(key string) []string {
+	return splitPathSegments(p.GetFirstString(key))
+}
+
+// splitPathSegments - splits the path into its (non-empty) segments
+func splitPathSegments(path string) []string {
+	trimmed := strings.Trim(path, "/")
+	if trimmed == "" {
+		return []string{}
+	}
+	return strings.Split(trimmed, "/")
+}
+
 func (p *Parameters) GetInts(key string) []int {
-	val := p.paramsMap[key]
+	val := p.values(key)
 	if val == nil || len(val) == 0 {
 		return []int{}
 	}
@@ -69,6 +133,78 @@ func (p *Parameters) GetInt(key string) int {
 
 }
 
+// GetFloats - returns all values for the key that were converted to a float64
+func (p *Parameters) GetFloats(key string) []float64 {
+	val := p.values(key)
+	if val == nil || len(val) == 0 {
+		return []float64{}
+	}
+	outArr := make([]float64, len(val))
+	for i, v := range val {
+		if n, ok := v.(float64); ok {
+			outArr[i] = n
+		}
+	}
+	return outArr
+}
+
+// GetFloat - returns the first float64 value for the key or 0
+func (p *Parameters) GetFloat(key string) float64 {
+	floatArr := p.GetFloats(key)
+	if len(floatArr) == 0 {
+		return 0
+	}
+	return floatArr[0]
+}
+
+// GetUUIDs - returns all values for the key that were converted to a UUID
+func (p *Parameters) GetUUIDs(key string) []UUID {
+	val := p.values(key)
+	if val == nil || len(val) == 0 {
+		return []UUID{}
+	}
+	outArr := make([]UUID, len(val))
+	for i, v := range val {
+		if n, ok := v.(UUID); ok {
+			outArr[i] = n
+		}
+	}
+	return outArr
+}
+
+// GetUUID - returns the first UUID value for the key or an empty UUID
+func (p *Parameters) GetUUID(key string) UUID {
+	uuidArr := p.GetUUIDs(key)
+	if len(uuidArr) == 0 {
+		return UUID{}
+	}
+	return uuidArr[0]
+}
+
+// GetTimes - returns all values for the key that were converted to a time.Time
+func (p *Parameters) GetTimes(key string) []time.Time {
+	val := p.values(key)
+	if val == nil || len(val) == 0 {
+		return []time.Time{}
+	}
+	outArr := make([]time.Time, len(val))
+	for i, v := range val {
+		if n, ok := v.(time.Time); ok {
+			outArr[i] = n
+		}
+	}
+	return outArr
+}
+
+// GetTime - returns the first time.Time value for the key or the zero time
+func (p *Parameters) GetTime(key string) time.Time {
+	timeArr := p.GetTimes(key)
+	if len(timeArr) == 0 {
+		return time.Time{}
+	}
+	return timeArr[0]
+}
+
 // converts a map of lists of strings to a generic interface
 func stringMapToInterfaceMap(stringMap map[string][]string) map[string][]interface{} {
 	outMap := map[string][]interface{}{}
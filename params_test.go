@@ -0,0 +1,63 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/theyakka/goro"
+)
+
+func TestTypedParams(t *testing.T) {
+	var params *goro.Parameters
+	typedRouter := goro.NewRouter()
+	captureParams := func(ctx *goro.HandlerContext) {
+		wasHit = true
+		params = ctx.Parameters
+	}
+	typedRouter.GET("/orders/:id<int>").HandleFunc(captureParams)
+	typedRouter.GET("/prices/:price<float>").HandleFunc(captureParams)
+	typedRouter.GET("/sessions/:uuid<uuid>").HandleFunc(captureParams)
+	typedRouter.GET("/events/:ts<time>").HandleFunc(captureParams)
+
+	expectHitResult(t, typedRouter, "GET", "/orders/42")
+	if params.GetInt("id") != 42 {
+		t.Error("Expected id to be 42 but got", params.GetInt("id"))
+	}
+	if params.GetFirstString("id") != "42" {
+		t.Error("Expected id string to be 42 but got", params.GetFirstString("id"))
+	}
+	expectNotHitResult(t, typedRouter, "GET", "/orders/forty-two")
+
+	expectHitResult(t, typedRouter, "GET", "/prices/10.5")
+	if params.GetFloat("price") != 10.5 {
+		t.Error("Expected price to be 10.5 but got", params.GetFloat("price"))
+	}
+	expectNotHitResult(t, typedRouter, "GET", "/prices/cheap")
+
+	uuidString := "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	expectHitResult(t, typedRouter, "GET", "/sessions/"+uuidString)
+	if params.GetUUID("uuid").String() != uuidString {
+		t.Error("Expected uuid to be", uuidString, "but got", params.GetUUID("uuid"))
+	}
+	expectNotHitResult(t, typedRouter, "GET", "/sessions/6ba7b810")
+
+	expectHitResult(t, typedRouter, "GET", "/events/2019-03-01T10:00:00Z")
+	expectedTime := time.Date(2019, 3, 1, 10, 0, 0, 0, time.UTC)
+	if !params.GetTime("ts").Equal(expectedTime) {
+		t.Error("Expected ts to be", expectedTime, "but got", params.GetTime("ts"))
+	}
+	expectHitResult(t, typedRouter, "GET", "/events/1551434400")
+	if !params.GetTime("ts").Equal(expectedTime) {
+		t.Error("Expected ts to be", expectedTime, "but got", params.GetTime("ts"))
+	}
+	expectNotHitResult(t, typedRouter, "GET", "/events/yesterday")
+}
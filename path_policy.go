@@ -0,0 +1,123 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// PathPolicy - how the router handles requests for paths that are not in their
+// canonical form. A path is canonical when it has no duplicate slashes, no '.' or
+// '..' segments, has a trailing slash only if the route was declared with one and
+// has fixed segments in the same case as the route declaration
+type PathPolicy int
+
+const (
+	// PathPolicyLenient - paths are cleaned using CleanPath and then matched
+	PathPolicyLenient PathPolicy = 1 << iota
+	// PathPolicyRedirect - requests for non-canonical paths are redirected to the
+	// canonical path (301 for GET / HEAD requests, 308 for all other methods)
+	PathPolicyRedirect
+	// PathPolicyStrict - non-canonical paths will not be matched
+	PathPolicyStrict
+)
+
+// SetPathPolicy - sets how the router will handle requests for non-canonical paths
+func (r *Router) SetPathPolicy(policy PathPolicy) {
+	r.pathPolicy = policy
+}
+
+// normalizePath - removes duplicate slashes and resolves any dot segments in the
+// path. a trailing slash is preserved
+func normalizePath(requestPath string) string {
+	normalized := strings.Replace(requestPath, "\\", "/", -1)
+	cleaned := path.Clean("/" + normalized)
+	if cleaned != RootPath && strings.HasSuffix(normalized, "/") {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// canonicalPath - rebuilds the normalized path using the declared case of the
+// matched node's fixed segments and the trailing slash declared by the route for
+// the method
+func canonicalPath(node *Node, method string, normalizedPath string) string {
+	if node.parent == nil && node.part == RootPath {
+		return RootPath
+	}
+	var chain []*Node
+	for current := node; current != nil; current = current.parent {
+		chain = append(chain, current)
+	}
+	segments := strings.Split(strings.Trim(normalizedPath, "/"), "/")
+	var builder strings.Builder
+	for segmentIdx := 0; segmentIdx < len(chain) && segmentIdx < len(segments); segmentIdx++ {
+		current := chain[len(chain)-1-segmentIdx]
+		switch current.nodeType {
+		case ComponentTypeCatchAll:
+			// the remainder of the path belongs to the catch-all
+			builder.WriteString("/" + strings.Join(segments[segmentIdx:], "/"))
+			if normalizedPath != RootPath && strings.HasSuffix(normalizedPath, "/") {
+				builder.WriteString("/")
+			}
+			return builder.String()
+		case ComponentTypeWildcard:
+			builder.WriteString("/" + segments[segmentIdx])
+		default:
+			builder.WriteString("/" + current.part)
+		}
+	}
+	if node.trailingSlashForMethod(method) || (node.nodeType == ComponentTypeCatchAll &&
+		normalizedPath != RootPath && strings.HasSuffix(normalizedPath, "/")) {
+		// catch-alls that match an empty remainder keep the requested trailing slash
+		builder.WriteString("/")
+	}
+	return builder.String()
+}
+
+// trailingSlashForMethod - returns true if the route for the method (or, if there
+// is no such route, any route at the node) was declared with a trailing slash
+func (node *Node) trailingSlashForMethod(method string) bool {
+	route := node.RouteForMethod(method)
+	if route == nil && method == http.MethodHead {
+		route = node.RouteForMethod(http.MethodGet)
+	}
+	if route != nil {
+		return route.hasTrailingSlash()
+	}
+	for _, nodeRoute := range node.allRoutes() {
+		if nodeRoute.hasTrailingSlash() {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTrailingSlash - returns true if the route was declared with a trailing slash
+func (rte *Route) hasTrailingSlash() bool {
+	return rte.PathFormat != RootPath && strings.HasSuffix(rte.PathFormat, "/")
+}
+
+// redirectToPath - redirects the request to the path, preserving the query string
+// and the prefix of the router (if it is mounted). GET and HEAD requests use 301
+// (Moved Permanently), all other methods use 308 (Permanent Redirect) so that the
+// method and body are preserved
+func (r *Router) redirectToPath(ctx *HandlerContext, redirectPath string) {
+	statusCode := http.StatusPermanentRedirect
+	if ctx.Request.Method == http.MethodGet || ctx.Request.Method == http.MethodHead {
+		statusCode = http.StatusMovedPermanently
+	}
+	location := url.URL{Path: ctx.mountPrefix + redirectPath, RawQuery: ctx.Request.URL.RawQuery}
+	http.Redirect(ctx.ResponseWriter, ctx.Request, location.String(), statusCode)
+	r.executePostFilters(ctx)
+}
@@ -0,0 +1,131 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/theyakka/goro"
+)
+
+func newPolicyRouter(policy goro.PathPolicy) *goro.Router {
+	policyRouter := goro.NewRouter()
+	policyRouter.SetPathPolicy(policy)
+	policyRouter.GET("/users/:id").HandleFunc(testHandler)
+	policyRouter.POST("/users/:id").HandleFunc(testHandler)
+	policyRouter.GET("/docs/").HandleFunc(testHandler)
+	policyRouter.GET("/static/*").HandleFunc(testHandler)
+	return policyRouter
+}
+
+func TestPathPolicyRedirect(t *testing.T) {
+	policyRouter := newPolicyRouter(goro.PathPolicyRedirect)
+	redirects := []struct {
+		method   string
+		path     string
+		status   int
+		location string
+	}{
+		{"GET", "/users/42/", http.StatusMovedPermanently, "/users/42"},
+		{"GET", "/users//42?full=1", http.StatusMovedPermanently, "/users/42?full=1"},
+		{"GET", "/users/./x/../42", http.StatusMovedPermanently, "/users/42"},
+		{"GET", "/USERS/Bob", http.StatusMovedPermanently, "/users/Bob"},
+		{"GET", "/docs", http.StatusMovedPermanently, "/docs/"},
+		{"POST", "/users/42/?x=y", http.StatusPermanentRedirect, "/users/42?x=y"},
+	}
+	for _, redirect := range redirects {
+		req, _ := http.NewRequest(redirect.method, redirect.path, nil)
+		w := httptest.NewRecorder()
+		policyRouter.ServeHTTP(w, req)
+		if w.Code != redirect.status || w.Header().Get("Location") != redirect.location {
+			t.Error("Expected", redirect.method, redirect.path, "to redirect with", redirect.status,
+				"to", redirect.location, "but got", w.Code, w.Header().Get("Location"))
+		}
+	}
+	if wasHit {
+		t.Error("Expected redirected requests to not hit the handler")
+	}
+	expectHitResult(t, policyRouter, "GET", "/users/42")
+	expectHitResult(t, policyRouter, "GET", "/docs/")
+	expectHitResult(t, policyRouter, "GET", "/static/css/site.css/")
+}
+
+func TestPathPolicyRedirectMounted(t *testing.T) {
+	parentRouter := goro.NewRouter()
+	parentRouter.Mount("/sub", newPolicyRouter(goro.PathPolicyRedirect))
+	nestedRouter := goro.NewRouter()
+	nestedRouter.Mount("/sub", newPolicyRouter(goro.PathPolicyRedirect))
+	parentRouter.Mount("/nested", nestedRouter)
+	// redirects from a mounted router keep the mount prefix
+	redirects := []struct {
+		path     string
+		location string
+	}{
+		{"/sub/docs", "/sub/docs/"},
+		{"/sub/users/42/?x=y", "/sub/users/42?x=y"},
+		{"/nested/sub/docs", "/nested/sub/docs/"},
+	}
+	for _, redirect := range redirects {
+		req, _ := http.NewRequest("GET", redirect.path, nil)
+		w := httptest.NewRecorder()
+		parentRouter.ServeHTTP(w, req)
+		if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != redirect.location {
+			t.Error("Expected", redirect.path, "to redirect to", redirect.location, "but got",
+				w.Code, w.Header().Get("Location"))
+		}
+	}
+	expectHitResult(t, parentRouter, "GET", "/sub/docs/")
+}
+
+func TestPathPolicyStrict(t *testing.T) {
+	policyRouter := newPolicyRouter(goro.PathPolicyStrict)
+	for _, path := range []string{"/users/42/", "/users//42", "/USERS/42", "/docs", "/users/x/../42"} {
+		expectNotHitResult(t, policyRouter, "GET", path)
+	}
+	expectHitResult(t, policyRouter, "GET", "/users/42")
+	expectHitResult(t, policyRouter, "GET", "/docs/")
+}
+
+func TestPathPolicyLenient(t *testing.T) {
+	policyRouter := newPolicyRouter(goro.PathPolicyLenient)
+	for _, path := range []string{"/users/42/", "/users//42", "/USERS/42", "/docs", "/docs/"} {
+		expectHitResult(t, policyRouter, "GET", path)
+	}
+}
+
+func TestPathPolicyMixedTrailingSlash(t *testing.T) {
+	policyRouter := goro.NewRouter()
+	policyRouter.SetPathPolicy(goro.PathPolicyRedirect)
+	policyRouter.GET("/users/").HandleFunc(testHandler)
+	policyRouter.POST("/users").HandleFunc(testHandler)
+	redirects := []struct {
+		method   string
+		path     string
+		status   int
+		location string
+	}{
+		{"GET", "/users", http.StatusMovedPermanently, "/users/"},
+		{"HEAD", "/users", http.StatusMovedPermanently, "/users/"},
+		{"POST", "/users/", http.StatusPermanentRedirect, "/users"},
+	}
+	for _, redirect := range redirects {
+		req, _ := http.NewRequest(redirect.method, redirect.path, nil)
+		w := httptest.NewRecorder()
+		policyRouter.ServeHTTP(w, req)
+		if w.Code != redirect.status || w.Header().Get("Location") != redirect.location {
+			t.Error("Expected", redirect.method, redirect.path, "to redirect with", redirect.status,
+				"to", redirect.location, "but got", w.Code, w.Header().Get("Location"))
+		}
+	}
+	expectHitResult(t, policyRouter, "GET", "/users/")
+	expectHitResult(t, policyRouter, "POST", "/users")
+}
@@ -0,0 +1,425 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro
+
+import (
+	"sort"
+	"strings"
+)
+
+// routeIndex - the compiled form of a route Tree that is used for matching. The
+// index is read-only once built and is rebuilt whenever the Tree changes.
+type routeIndex struct {
+	// root - the root of the tree containing routes for all methods
+	root *radixNode
+
+	// methodRoots - per-method roots containing only the routes for that method
+	methodRoots map[string]*radixNode
+}
+
+// radixNode - a node in the compressed matching tree. Chains of fixed parts that
+// have no routes of their own are collapsed into a single node (e.g.: the parts
+// 'api', 'v1' and 'docs' become a single node with the prefix 'api/v1/docs').
+type radixNode struct {
+	// prefix - the fixed path text matched by the node. empty for wildcards and
+	// catch-alls
+	prefix string
+
+	// kind - the type of path component matched by the node
+	kind RouteComponentType
+
+	// source - the tree node that the radix node terminates at
+	source *Node
+
+	// paramKey - the parameter key (in the declared case) for wildcard and named
+	// catch-all nodes
+	paramKey string
+
+	// hasRoutes - true if a match can terminate at this node
+	hasRoutes bool
+
+	// matchesEmpty - true if the node is a catch-all that also matches an empty
+	// remainder (i.e.: a '*' catch-all)
+	matchesEmpty bool
+
+	// priority - the highest Route priority at or below the node
+	priority int
+
+	// ordered - all of the children in precedence order. only set if the children
+	// have different priorities (otherwise the faster, class based, walk is used)
+	ordered []*radixNode
+
+	// indices - the lower case first byte of each of the fixed children. a zero
+	// value indicates that the child must always be checked (non-ascii prefix)
+	indices []byte
+
+	// statics - fixed children (aligned with indices)
+	statics []*radixNode
+
+	// wildcards - wildcard children, in registration order
+	wildcards []*radixNode
+
+	// catchAlls - catch-all children, in registration order
+	catchAlls []*radixNode
+}
+
+// newRouteIndex - compiles the tree into a routeIndex
+func newRouteIndex(tree *Tree) *routeIndex {
+	index := &routeIndex{
+		root:        compileTree(tree, ""),
+		methodRoots: map[string]*radixNode{},
+	}
+	for _, method := range treeMethods(tree.nodes, map[string]bool{}) {
+		index.methodRoots[method] = compileTree(tree, method)
+	}
+	return index
+}
+
+// treeMethods - returns all the methods that routes have been registered for
+func treeMethods(nodes []*Node, seen map[string]bool) []string {
+	var methods []string
+	for _, node := range nodes {
+		for method := range node.routes {
+			if !seen[method] {
+				seen[method] = true
+				methods = append(methods, method)
+			}
+		}
+		methods = append(methods, treeMethods(node.nodes, seen)...)
+	}
+	return methods
+}
+
+// compileTree - compiles the top-level tree nodes. If method is not empty then only
+// routes for that method will be included
+func compileTree(tree *Tree, method string) *radixNode {
+	root := &radixNode{
+		kind: ComponentTypeFixed,
+	}
+	var children []*radixNode
+	for _, node := range tree.nodes {
+		if node.part == RootPath {
+			root.source = node
+			root.hasRoutes = nodeHasRoutes(node, method)
+			continue
+		}
+		if child := compileNode(node, method); child != nil {
+			children = append(children, child)
+		}
+	}
+	if !root.hasRoutes && len(children) == 0 {
+		return nil
+	}
+	root.addChildren(children)
+	return root
+}
+
+// compileNode - compiles the node and all of its children. returns nil if neither
+// the node nor its children have any routes
+func compileNode(node *Node, method string) *radixNode {
+	var children []*radixNode
+	for _, child := range node.nodes {
+		if compiled := compileNode(child, method); compiled != nil {
+			children = append(children, compiled)
+		}
+	}
+	hasRoutes := nodeHasRoutes(node, method)
+	if !hasRoutes && len(children) == 0 {
+		return nil
+	}
+	if node.nodeType == ComponentTypeFixed && !hasRoutes && len(children) == 1 &&
+		children[0].kind == ComponentTypeFixed {
+		// compress the chain of fixed nodes
+		compressed := children[0]
+		compressed.prefix = node.part + "/" + compressed.prefix
+		return compressed
+	}
+	compiled := &radixNode{
+		kind:         node.nodeType,
+		source:       node,
+		hasRoutes:    hasRoutes,
+		matchesEmpty: hasRoutes && node.matchesEmptyRemainder(),
+		priority:     node.routesPriority(method),
+	}
+	switch node.nodeType {
+	case ComponentTypeFixed:
+		compiled.prefix = node.part
+	default:
+		compiled.paramKey = node.paramName
+	}
+	compiled.addChildren(children)
+	return compiled
+}
+
+// nodeHasRoutes - returns true if the node has a route for the method or, if the
+// method is empty, any routes at all
+func nodeHasRoutes(node *Node, method string) bool {
+	if method == "" {
+		for _, methodRoutes := range node.routes {
+			for _, route := range methodRoutes {
+				if !route.IsDisabled() {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return node.RouteForMethod(method) != nil
+}
+
+// routesPriority - returns the highest priority of the routes at the node (for the
+// method, if not empty)
+func (node *Node) routesPriority(method string) int {
+	priority := 0
+	first := true
+	for routeMethod, methodRoutes := range node.routes {
+		if method != "" && routeMethod != method && routeMethod != MethodAny {
+			continue
+		}
+		for _, route := range methodRoutes {
+			if routePriority := route.matchPriority(); first || routePriority > priority {
+				priority = routePriority
+				first = false
+			}
+		}
+	}
+	return priority
+}
+
+// classRank - ranks the kind of node for precedence (lower is checked first)
+func (rn *radixNode) classRank() int {
+	switch rn.kind {
+	case ComponentTypeFixed:
+		return 0
+	case ComponentTypeWildcard:
+		return 1
+	}
+	return 2
+}
+
+// constraintRank - ranks how constrained a wildcard is (higher is checked first).
+// wildcards containing fixed text are the most constrained
+func (rn *radixNode) constraintRank() int {
+	if rn.source == nil {
+		return 0
+	}
+	if rn.source.pattern != nil {
+		return 3
+	}
+	rank := 0
+	if rn.source.regexp != nil {
+		rank++
+	}
+	if rn.source.paramType != ParamTypeString {
+		rank++
+	}
+	return rank
+}
+
+// precedes - returns true if the node should be checked before the other node:
+// higher priority first, then fixed parts, wildcards and catch-alls, then the more
+// constrained wildcards and, finally, alphabetically by part
+func (rn *radixNode) precedes(other *radixNode) bool {
+	if rn.priority != other.priority {
+		return rn.priority > other.priority
+	}
+	if rn.classRank() != other.classRank() {
+		return rn.classRank() < other.classRank()
+	}
+	if rn.constraintRank() != other.constraintRank() {
+		return rn.constraintRank() > other.constraintRank()
+	}
+	return rn.sortPart() < other.sortPart()
+}
+
+// sortPart - the part used to break precedence ties
+func (rn *radixNode) sortPart() string {
+	if rn.kind == ComponentTypeFixed || rn.source == nil {
+		return rn.prefix
+	}
+	return rn.source.part
+}
+
+// addChildren - sorts the children into the fixed, wildcard and catch-all sets (in
+// precedence order) and records the highest priority of the children
+func (rn *radixNode) addChildren(children []*radixNode) {
+	sort.SliceStable(children, func(i, j int) bool {
+		return children[i].precedes(children[j])
+	})
+	prioritized := false
+	for _, child := range children {
+		if child.priority > rn.priority {
+			rn.priority = child.priority
+		}
+		if child.priority != children[0].priority {
+			prioritized = true
+		}
+	}
+	if prioritized {
+		rn.ordered = children
+	}
+	for _, child := range children {
+		switch child.kind {
+		case ComponentTypeFixed:
+			rn.indices = append(rn.indices, indexByteForPrefix(child.prefix))
+			rn.statics = append(rn.statics, child)
+		case ComponentTypeWildcard:
+			rn.wildcards = append(rn.wildcards, child)
+		case ComponentTypeCatchAll:
+			rn.catchAlls = append(rn.catchAlls, child)
+		}
+	}
+}
+
+// indexByteForPrefix - returns the index byte used to look up a fixed prefix
+func indexByteForPrefix(prefix string) byte {
+	if prefix == "" || prefix[0] >= 0x80 {
+		return 0
+	}
+	return lowerASCII(prefix[0])
+}
+
+// lowerASCII - returns the lower case version of an ascii byte
+func lowerASCII(b byte) byte {
+	if b >= 'A' && b <= 'Z' {
+		return b + ('a' - 'A')
+	}
+	return b
+}
+
+// walk - matches the remainder of the path (starting at pos) against the children
+// of the node. fixed children are checked first, then wildcards. catch-alls are
+// recorded as fallbacks so that a full match always takes precedence
+func (match *Match) walk(node *radixNode, pos int) *radixNode {
+	path := match.path
+	if pos >= len(path) {
+		if node.hasRoutes {
+			return node
+		}
+		for _, child := range node.catchAlls {
+			if child.matchesEmpty && pos > match.catchAllPos {
+				match.recordCatchAll(child, len(match.fullPath))
+				break
+			}
+		}
+		return nil
+	}
+	if node.ordered != nil {
+		return match.walkOrdered(node, pos)
+	}
+	firstByte := lowerASCII(path[pos])
+	for idx, indexByte := range node.indices {
+		if indexByte != firstByte && indexByte != 0 {
+			continue
+		}
+		if found := match.walkStatic(node.statics[idx], pos); found != nil {
+			return found
+		}
+	}
+	segmentEnd := match.segmentEnd(pos)
+	for _, child := range node.wildcards {
+		if found := match.walkWildcard(child, pos, segmentEnd); found != nil {
+			return found
+		}
+	}
+	for _, child := range node.catchAlls {
+		if child.hasRoutes && pos > match.catchAllPos {
+			// deeper catch-alls take precedence
+			match.recordCatchAll(child, pos)
+		}
+	}
+	return nil
+}
+
+// walkOrdered - matches the remainder of the path against the children of the node
+// in precedence order. used when the children have different priorities so that a
+// higher priority child is always checked first (regardless of the kind of part).
+// a catch-all is used immediately if no higher priority catch-all was recorded
+func (match *Match) walkOrdered(node *radixNode, pos int) *radixNode {
+	segmentEnd := match.segmentEnd(pos)
+	recordedPos := match.catchAllPos
+	for _, child := range node.ordered {
+		var found *radixNode
+		switch child.kind {
+		case ComponentTypeFixed:
+			found = match.walkStatic(child, pos)
+		case ComponentTypeWildcard:
+			found = match.walkWildcard(child, pos, segmentEnd)
+		case ComponentTypeCatchAll:
+			if child.hasRoutes && match.catchAllPos == recordedPos {
+				match.recordCatchAll(child, pos)
+				return child
+			}
+		}
+		if found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// walkStatic - matches the fixed child at pos and then walks its children
+func (match *Match) walkStatic(child *radixNode, pos int) *radixNode {
+	path := match.path
+	end := pos + len(child.prefix)
+	if end > len(path) || (end < len(path) && path[end] != '/') {
+		return nil
+	}
+	if part := path[pos:end]; part != child.prefix {
+		// only fall back to the (slower) case-insensitive comparison if required
+		if match.caseSensitive || !strings.EqualFold(part, child.prefix) {
+			return nil
+		}
+	}
+	return match.walk(child, end+1)
+}
+
+// walkWildcard - matches the wildcard child to the segment and then walks its
+// children
+func (match *Match) walkWildcard(child *radixNode, pos int, segmentEnd int) *radixNode {
+	segment := match.path[pos:segmentEnd]
+	paramCount := len(match.params)
+	if child.source.pattern != nil {
+		if !match.appendPatternParams(child.source, segment) {
+			return nil
+		}
+	} else {
+		if !child.source.acceptsValue(segment) {
+			return nil
+		}
+		match.params = append(match.params, matchParam{
+			node:  child.source,
+			key:   child.paramKey,
+			value: segment,
+		})
+	}
+	if found := match.walk(child, segmentEnd+1); found != nil {
+		return found
+	}
+	match.params = match.params[:paramCount]
+	return nil
+}
+
+// segmentEnd - returns the index of the end of the path segment starting at pos
+func (match *Match) segmentEnd(pos int) int {
+	segmentEnd := strings.IndexByte(match.path[pos:], '/')
+	if segmentEnd == -1 {
+		return len(match.path)
+	}
+	return segmentEnd + pos
+}
+
+// recordCatchAll - stores the catch-all (and the current parameters) so that it can
+// be used if no full match is found
+func (match *Match) recordCatchAll(node *radixNode, pos int) {
+	match.catchAllNode = node
+	match.catchAllPos = pos
+	match.catchAllParams = append(match.catchAllParams[:0], match.params...)
+}
@@ -0,0 +1,297 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// RegistrationErrorCode - type used to represent a problem registering a route
+type RegistrationErrorCode int
+
+const (
+	// RegistrationErrorDuplicateRoute - a route was already registered for the same
+	// method and path
+	RegistrationErrorDuplicateRoute RegistrationErrorCode = 1 << iota
+	// RegistrationErrorAmbiguousWildcard - sibling wildcards at the same depth cannot
+	// be told apart (e.g.: ':id' and ':userId')
+	RegistrationErrorAmbiguousWildcard
+	// RegistrationErrorCatchAllWithChildren - a catch-all part has parts after it
+	// which can never be matched
+	RegistrationErrorCatchAllWithChildren
+	// RegistrationErrorUnresolvedVariable - the route uses a variable that has no
+	// value definition
+	RegistrationErrorUnresolvedVariable
+	// RegistrationErrorInvalidPart - a wildcard part has an unknown type or an invalid
+	// constraint
+	RegistrationErrorInvalidPart
+	// RegistrationErrorInvalidMethod - the route method is not a valid HTTP method
+	// token (e.g.: it is empty or contains spaces)
+	RegistrationErrorInvalidMethod
+	// RegistrationErrorDuplicateName - another route was already registered using the
+	// same name (see Route.Name)
+	RegistrationErrorDuplicateName
+)
+
+// RegistrationError - describes a problem with a route registration
+type RegistrationError struct {
+	Code    RegistrationErrorCode
+	Method  string
+	Path    string
+	Message string
+
+	// File - the source file where the offending route was created
+	File string
+
+	// Line - the line in the source file where the offending route was created
+	Line int
+}
+
+// NewRegistrationError - creates a new RegistrationError for the offending route
+func NewRegistrationError(code RegistrationErrorCode, route *Route, message string) RegistrationError {
+	return RegistrationError{
+		Code:    code,
+		Method:  route.Method,
+		Path:    route.PathFormat,
+		Message: message,
+		File:    route.sourceFile,
+		Line:    route.sourceLine,
+	}
+}
+
+// Error - implement the error interface
+func (re RegistrationError) Error() string {
+	return fmt.Sprintf("%s %s: %s (%s:%d)", re.Method, re.Path, re.Message, re.File, re.Line)
+}
+
+// SetStrictRegistration - if true, the router will panic with a RegistrationError
+// as soon as a problematic route is passed to Use (the offending route is not
+// registered). Otherwise, problems are recorded and can be retrieved using Validate.
+// Routes are checked for duplicates using the request conditions they have when
+// they are registered, so conditions that tell routes apart should be added before
+// the route is passed to Use (e.g.: using NewRoute). Changing the conditions of a
+// registered route so that it duplicates another route will also panic
+func (r *Router) SetStrictRegistration(strict bool) {
+	r.strictRegistration = strict
+}
+
+// Validate - returns all the problems found with the routes registered with the
+// Router. Unresolved variables and duplicate route names are recorded when the
+// route is registered. Duplicate routes, ambiguous wildcards and catch-alls with
+// children are found by inspecting the route tree
+func (r *Router) Validate() []RegistrationError {
+	errs := make([]RegistrationError, 0, len(r.registrationErrors))
+	errs = append(errs, r.registrationErrors...)
+	return append(errs, validateNodes(r.Tree().nodes)...)
+}
+
+// recordRegistrationError - stores the error or panics if strict registration is on
+func (r *Router) recordRegistrationError(err error) {
+	regErr, ok := err.(RegistrationError)
+	if !ok {
+		return
+	}
+	if r.strictRegistration {
+		panic(regErr)
+	}
+	r.registrationErrors = append(r.registrationErrors, regErr)
+}
+
+// checkRouteConditions - with strict registration, panics with a RegistrationError
+// if the request conditions of the registered route were changed so that it now
+// duplicates another route. Without strict registration the duplicate is reported
+// by Validate
+func (r *Router) checkRouteConditions(route *Route) {
+	if !r.strictRegistration {
+		return
+	}
+	r.tableMutex.Lock()
+	defer r.tableMutex.Unlock()
+	if duplicateErr, found := routeDuplicateError(route, r.workingTree.nodesForRoute(route)); found {
+		panic(duplicateErr)
+	}
+}
+
+// validateRoutePath - checks the nodes that the route was added at (and the nodes
+// above them) for structural problems. only the part of the tree that changed is
+// checked so that each route can be validated as it is registered
+func validateRoutePath(tree *Tree, route *Route, nodes []*Node) []RegistrationError {
+	var errs []RegistrationError
+	for _, leaf := range nodes {
+		for node := leaf; node != nil; node = node.parent {
+			siblings := tree.nodes
+			if node.parent != nil {
+				siblings = node.parent.nodes
+			}
+			if node.nodeType == ComponentTypeWildcard {
+				for _, sibling := range siblings {
+					if sibling != node && sibling.nodeType == ComponentTypeWildcard && node.isAmbiguousWith(sibling) {
+						errs = append(errs, NewRegistrationError(RegistrationErrorAmbiguousWildcard, route,
+							ambiguousWildcardMessage(node, sibling)))
+						break
+					}
+				}
+			}
+			if node.nodeType == ComponentTypeCatchAll && node.HasChildren() {
+				errs = append(errs, NewRegistrationError(RegistrationErrorCatchAllWithChildren, route,
+					catchAllWithChildrenMessage(node)))
+			}
+		}
+	}
+	return errs
+}
+
+// validateNodes - recursively checks the nodes for structural problems
+func validateNodes(nodes []*Node) []RegistrationError {
+	var errs []RegistrationError
+	for idx, node := range nodes {
+		if node.nodeType == ComponentTypeWildcard {
+			for _, sibling := range nodes[:idx] {
+				if sibling.nodeType == ComponentTypeWildcard && node.isAmbiguousWith(sibling) {
+					errs = append(errs, NewRegistrationError(RegistrationErrorAmbiguousWildcard,
+						node.firstRoute(), ambiguousWildcardMessage(node, sibling)))
+					break
+				}
+			}
+		}
+		errs = append(errs, duplicateRouteErrors(node)...)
+		if node.nodeType == ComponentTypeCatchAll && node.HasChildren() {
+			message := catchAllWithChildrenMessage(node)
+			for _, child := range node.nodes {
+				errs = append(errs, NewRegistrationError(RegistrationErrorCatchAllWithChildren,
+					child.firstRoute(), message))
+			}
+		}
+		errs = append(errs, validateNodes(node.nodes)...)
+	}
+	return errs
+}
+
+// ambiguousWildcardMessage - describes an ambiguous wildcard
+func ambiguousWildcardMessage(node *Node, sibling *Node) string {
+	return fmt.Sprintf("wildcard '%s' is ambiguous with sibling wildcard '%s'", node.part, sibling.part)
+}
+
+// catchAllWithChildrenMessage - describes a catch-all with child parts
+func catchAllWithChildrenMessage(node *Node) string {
+	return fmt.Sprintf("catch-all '%s' cannot have child parts", node.part)
+}
+
+// isAmbiguousWith - two wildcard nodes are ambiguous if they accept exactly the
+// same values but are stored under different names. nodes that were both added for
+// the same route with optional parts are never ambiguous. a pattern (e.g.:
+// ':name.:ext') is only ambiguous with another pattern that has the same shape
+func (node *Node) isAmbiguousWith(other *Node) bool {
+	if node.optionalRoute != nil && node.optionalRoute == other.optionalRoute {
+		return false
+	}
+	if node.pattern != nil || other.pattern != nil {
+		return node.pattern != nil && other.pattern != nil &&
+			node.pattern.shape() == other.pattern.shape()
+	}
+	if node.paramType != other.paramType {
+		return false
+	}
+	if (node.regexp == nil) != (other.regexp == nil) {
+		return false
+	}
+	if node.regexp != nil && node.regexp.String() != other.regexp.String() {
+		return false
+	}
+	return true
+}
+
+// duplicateRouteErrors - returns an error for each route that is registered for the
+// same method (and with the same request conditions) as an earlier route
+func duplicateRouteErrors(node *Node) []RegistrationError {
+	var errs []RegistrationError
+	for _, method := range node.methods() {
+		for _, route := range node.routes[method] {
+			if duplicateErr, found := duplicateRouteError(node, method, route); found {
+				errs = append(errs, duplicateErr)
+			}
+		}
+	}
+	return errs
+}
+
+// duplicateRouteError - returns an error if a route that was registered at the node
+// for the method before the route has the same request conditions
+func duplicateRouteError(node *Node, method string, route *Route) (RegistrationError, bool) {
+	for _, existing := range node.routes[method] {
+		if existing == route {
+			break
+		}
+		if existing.conditionsKey() == route.conditionsKey() {
+			return newDuplicateRouteError(method, route, existing), true
+		}
+	}
+	return RegistrationError{}, false
+}
+
+// routeDuplicateError - returns an error if any other route registered at the nodes
+// for one of the methods of the route has the same request conditions
+func routeDuplicateError(route *Route, nodes []*Node) (RegistrationError, bool) {
+	for _, node := range nodes {
+		for _, method := range route.Methods() {
+			for _, existing := range node.routes[method] {
+				if existing != route && existing.conditionsKey() == route.conditionsKey() {
+					return newDuplicateRouteError(method, route, existing), true
+				}
+			}
+		}
+	}
+	return RegistrationError{}, false
+}
+
+// newDuplicateRouteError - creates the error for a route that duplicates another
+func newDuplicateRouteError(method string, route *Route, existing *Route) RegistrationError {
+	duplicateErr := NewRegistrationError(RegistrationErrorDuplicateRoute, route,
+		fmt.Sprintf("route is already registered at %s:%d", existing.sourceFile, existing.sourceLine))
+	duplicateErr.Method = method
+	return duplicateErr
+}
+
+// firstRoute - returns the first route found at or below the node
+func (node *Node) firstRoute() *Route {
+	for _, methodRoutes := range node.routes {
+		if len(methodRoutes) > 0 {
+			return methodRoutes[0]
+		}
+	}
+	for _, child := range node.nodes {
+		if route := child.firstRoute(); route != nil {
+			return route
+		}
+	}
+	return &Route{PathFormat: node.part}
+}
+
+// goroPackagePath - the import path of this package (used to skip internal frames)
+var goroPackagePath = reflect.TypeOf(Router{}).PkgPath()
+
+// callerLocation - returns the file and line of the first caller outside of goro
+func callerLocation() (file string, line int) {
+	pcs := make([]uintptr, 16)
+	count := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:count])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, goroPackagePath+".") {
+			return frame.File, frame.Line
+		}
+		if !more {
+			return frame.File, frame.Line
+		}
+	}
+}
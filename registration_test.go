@@ -0,0 +1,128 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/theyakka/goro"
+)
+
+func TestValidateRoutes(t *testing.T) {
+	if errs := router.Validate(); len(errs) > 0 {
+		t.Error("Expected the test router to be valid but got", errs)
+	}
+}
+
+func TestValidateRegistrationErrors(t *testing.T) {
+	badRouter := goro.NewRouter()
+	badRouter.GET("/users/:id").HandleFunc(testHandler)
+	badRouter.GET("/users/:id").HandleFunc(testHandler)
+	badRouter.GET("/users/:userId/posts").HandleFunc(testHandler)
+	badRouter.GET("/static/*").HandleFunc(testHandler)
+	badRouter.GET("/static/*/more").HandleFunc(testHandler)
+	badRouter.GET("/colors/$missing").HandleFunc(testHandler)
+	badRouter.Add("BAD METHOD", "/things").HandleFunc(testHandler)
+	expectedCodes := map[goro.RegistrationErrorCode]bool{
+		goro.RegistrationErrorDuplicateRoute:       false,
+		goro.RegistrationErrorAmbiguousWildcard:    false,
+		goro.RegistrationErrorCatchAllWithChildren: false,
+		goro.RegistrationErrorUnresolvedVariable:   false,
+		goro.RegistrationErrorInvalidMethod:        false,
+	}
+	for _, regErr := range badRouter.Validate() {
+		expectedCodes[regErr.Code] = true
+		if !strings.HasSuffix(regErr.File, "registration_test.go") || regErr.Line == 0 {
+			t.Error("Expected the error to reference the test file but got", regErr.File, regErr.Line)
+		}
+	}
+	for code, found := range expectedCodes {
+		if !found {
+			t.Error("Expected a registration error with code", code)
+		}
+	}
+	// constraints only apply to a single segment so they cannot contain a '/'
+	constraintRouter := goro.NewRouter()
+	constraintRouter.GET("/pages/:p(a/b)").HandleFunc(testHandler)
+	constraintRouter.GET("/things/:p<a/b>").HandleFunc(testHandler)
+	errs := constraintRouter.Validate()
+	if len(errs) != 2 || errs[0].Code != goro.RegistrationErrorInvalidPart || errs[1].Code != goro.RegistrationErrorInvalidPart {
+		t.Error("Expected invalid part errors for constraints containing '/' but got", errs)
+	}
+	// constrained wildcards are not ambiguous
+	okRouter := goro.NewRouter()
+	okRouter.GET("/items/:id<int>").HandleFunc(testHandler)
+	okRouter.GET("/items/:slug").HandleFunc(testHandler)
+	if errs := okRouter.Validate(); len(errs) > 0 {
+		t.Error("Expected no registration errors but got", errs)
+	}
+}
+
+func TestPatternRegistration(t *testing.T) {
+	// a pattern is never ambiguous with a plain wildcard
+	patternRouter := goro.NewRouter()
+	patternRouter.SetStrictRegistration(true)
+	patternRouter.GET("/files/:id").HandleFunc(testHandler)
+	patternRouter.GET("/files/:name.:ext").HandleFunc(testHandler)
+	patternRouter.GET("/files/:name-:version").HandleFunc(testHandler)
+	if errs := patternRouter.Validate(); len(errs) > 0 {
+		t.Error("Expected no registration errors but got", errs)
+	}
+	// patterns with the same shape are ambiguous
+	ambiguousRouter := goro.NewRouter()
+	ambiguousRouter.GET("/files/:name.:ext").HandleFunc(testHandler)
+	ambiguousRouter.GET("/files/:base.:format").HandleFunc(testHandler)
+	errs := ambiguousRouter.Validate()
+	if len(errs) != 1 || errs[0].Code != goro.RegistrationErrorAmbiguousWildcard {
+		t.Error("Expected an ambiguous wildcard error but got", errs)
+	}
+}
+
+func TestStrictRegistration(t *testing.T) {
+	expectRegistrationPanic := func(description string, register func()) {
+		defer func() {
+			if recovered := recover(); recovered == nil {
+				t.Error("Expected a RegistrationError panic for", description)
+			} else if _, ok := recovered.(goro.RegistrationError); !ok {
+				t.Error("Expected a RegistrationError panic for", description, "but got", recovered)
+			}
+		}()
+		register()
+	}
+	strictRouter := goro.NewRouter()
+	strictRouter.SetStrictRegistration(true)
+	strictRouter.GET("/users/:id").HandleFunc(testHandler)
+	// conditions that tell routes apart are added before the route is registered
+	strictRouter.Use(goro.NewRoute("GET", "/users/:id").Headers("X-Beta", "1").HandleFunc(testHandler))
+	strictRouter.GET("/teams").HandleFunc(testHandler)
+	expectRegistrationPanic("an ambiguous wildcard", func() {
+		strictRouter.GET("/users/:userId/posts").HandleFunc(testHandler)
+	})
+	expectRegistrationPanic("a duplicate route", func() {
+		strictRouter.GET("/teams").HandleFunc(testHandler)
+	})
+	// changing the conditions of a registered route can make it a duplicate
+	strictRouter.GET("/projects").Headers("X-Beta", "1").HandleFunc(testHandler)
+	strictRouter.GET("/projects").Headers("X-Beta", "2").HandleFunc(testHandler)
+	expectRegistrationPanic("a route whose conditions make it a duplicate", func() {
+		strictRouter.Use(goro.NewRoute("GET", "/projects"))[0].Headers("X-Beta", "1")
+	})
+	// routes that fail to register are not added. the route with changed conditions
+	// remains registered and is reported by Validate
+	if errs := strictRouter.Validate(); len(errs) != 1 || errs[0].Code != goro.RegistrationErrorDuplicateRoute {
+		t.Error("Expected only the route with changed conditions to be reported but got", errs)
+	}
+	if status := statusFor(strictRouter, "GET", "/users/1/posts"); status != http.StatusNotFound {
+		t.Error("Expected the ambiguous route not to be registered but got", status)
+	}
+	expectHitResult(t, strictRouter, "GET", "/teams")
+}
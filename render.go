@@ -0,0 +1,253 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	// MIMEApplicationJSON - the media type used by HandlerContext.JSON
+	MIMEApplicationJSON = "application/json"
+	// MIMEApplicationXML - the media type used by HandlerContext.XML
+	MIMEApplicationXML = "application/xml"
+	// MIMETextPlain - the media type used by HandlerContext.Text
+	MIMETextPlain = "text/plain"
+	// MIMETextHTML - the media type used by HandlerContext.HTML
+	MIMETextHTML = "text/html"
+)
+
+// ErrResponseWritten - the response status and headers have already been written
+var ErrResponseWritten = errors.New("the response has already been written")
+
+// Encoder - converts a value into a response body (see Router.SetEncoder)
+type Encoder interface {
+	Encode(w io.Writer, v interface{}) error
+}
+
+// EncoderFunc - a function that implements the Encoder interface
+type EncoderFunc func(w io.Writer, v interface{}) error
+
+// Encode - implement the Encoder interface
+func (ef EncoderFunc) Encode(w io.Writer, v interface{}) error {
+	return ef(w, v)
+}
+
+// JSONEncoder - the default encoder for JSON responses
+var JSONEncoder Encoder = EncoderFunc(func(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+})
+
+// XMLEncoder - the default encoder for XML responses. The XML header is written
+// before the value
+var XMLEncoder Encoder = EncoderFunc(func(w io.Writer, v interface{}) error {
+	if _, writeErr := io.WriteString(w, xml.Header); writeErr != nil {
+		return writeErr
+	}
+	return xml.NewEncoder(w).Encode(v)
+})
+
+// defaultEncoders - the encoders that every Router starts with
+func defaultEncoders() map[string]Encoder {
+	return map[string]Encoder{
+		MIMEApplicationJSON: JSONEncoder,
+		MIMEApplicationXML:  XMLEncoder,
+	}
+}
+
+// SetEncoder registers the encoder used to render values for the media type (e.g.:
+// 'text/csv'). Encoders for 'application/json' and 'application/xml' are registered
+// by default and can be replaced. Setting a nil encoder removes it. The order that
+// media types are first registered in is their order of preference when negotiating
+// (see HandlerContext.Negotiate)
+func (r *Router) SetEncoder(mediaType string, encoder Encoder) {
+	mediaType = strings.ToLower(mediaType)
+	if encoder == nil {
+		delete(r.encoders, mediaType)
+		r.encoderTypes = removeString(r.encoderTypes, mediaType)
+		return
+	}
+	if _, exists := r.encoders[mediaType]; !exists {
+		r.encoderTypes = append(r.encoderTypes, mediaType)
+	}
+	r.encoders[mediaType] = encoder
+}
+
+// SetTemplates sets the templates used by HandlerContext.HTML
+func (r *Router) SetTemplates(templates *template.Template) {
+	r.templates = templates
+}
+
+// encoderForType - returns the encoder for the (case-insensitive) media type,
+// checking the parent routers if this router has been mounted
+func (r *Router) encoderForType(mediaType string) Encoder {
+	mediaType = strings.ToLower(mediaType)
+	for router := r; router != nil; router = router.parent {
+		if encoder := router.encoders[mediaType]; encoder != nil {
+			return encoder
+		}
+	}
+	return nil
+}
+
+// templatesForRender - returns the templates, checking the parent routers if this
+// router has been mounted
+func (r *Router) templatesForRender() *template.Template {
+	for router := r; router != nil; router = router.parent {
+		if router.templates != nil {
+			return router.templates
+		}
+	}
+	return nil
+}
+
+// Render encodes v using the encoder registered for the media type and writes it
+// with the status code. The Content-Type is set to the media type (with a utf-8
+// charset for JSON, XML and text types). Nothing is written if encoding fails
+func (hc *HandlerContext) Render(status int, mediaType string, v interface{}) error {
+	var encoder Encoder
+	if hc.router != nil {
+		encoder = hc.router.encoderForType(mediaType)
+	} else {
+		encoder = defaultEncoders()[strings.ToLower(mediaType)]
+	}
+	if encoder == nil {
+		return fmt.Errorf("no encoder registered for '%s'", mediaType)
+	}
+	var body bytes.Buffer
+	if encodeErr := encoder.Encode(&body, v); encodeErr != nil {
+		return encodeErr
+	}
+	return hc.writeResponse(status, contentTypeWithCharset(mediaType), body.Bytes())
+}
+
+// JSON writes v as JSON with the status code
+func (hc *HandlerContext) JSON(status int, v interface{}) error {
+	return hc.Render(status, MIMEApplicationJSON, v)
+}
+
+// XML writes v as XML with the status code
+func (hc *HandlerContext) XML(status int, v interface{}) error {
+	return hc.Render(status, MIMEApplicationXML, v)
+}
+
+// Text writes the text as plain text with the status code
+func (hc *HandlerContext) Text(status int, text string) error {
+	return hc.writeResponse(status, contentTypeWithCharset(MIMETextPlain), []byte(text))
+}
+
+// HTML executes the named template (see Router.SetTemplates) with the data and writes
+// the result with the status code. Nothing is written if the template fails
+func (hc *HandlerContext) HTML(status int, templateName string, data interface{}) error {
+	var templates *template.Template
+	if hc.router != nil {
+		templates = hc.router.templatesForRender()
+	}
+	if templates == nil {
+		return errors.New("no templates have been set")
+	}
+	var body bytes.Buffer
+	if executeErr := templates.ExecuteTemplate(&body, templateName, data); executeErr != nil {
+		return executeErr
+	}
+	return hc.writeResponse(status, contentTypeWithCharset(MIMETextHTML), body.Bytes())
+}
+
+// Blob writes the data with the content type and status code
+func (hc *HandlerContext) Blob(status int, contentType string, data []byte) error {
+	return hc.writeResponse(status, contentType, data)
+}
+
+// NoContent writes the status code without a body
+func (hc *HandlerContext) NoContent(status int) error {
+	return hc.writeResponse(status, "", nil)
+}
+
+// Redirect redirects the request to the url using the status code, which must be a
+// redirect status code (3xx)
+func (hc *HandlerContext) Redirect(status int, url string) error {
+	if status < http.StatusMultipleChoices || status > http.StatusPermanentRedirect {
+		return fmt.Errorf("invalid redirect status code %d", status)
+	}
+	if hc.responseWritten() {
+		return ErrResponseWritten
+	}
+	http.Redirect(hc.ResponseWriter, hc.Request, url, status)
+	return nil
+}
+
+// RedirectToRoute redirects the request to the URL generated for the named route (see
+// Router.URL). GET and HEAD requests are redirected using 302 (Found), all other
+// methods use 303 (See Other) so that the client follows the redirect using GET
+func (hc *HandlerContext) RedirectToRoute(name string, params P) error {
+	if hc.router == nil {
+		return errors.New("the context does not belong to a router")
+	}
+	url, urlErr := hc.router.URL(name, params)
+	if urlErr != nil {
+		return urlErr
+	}
+	status := http.StatusSeeOther
+	if hc.Request.Method == http.MethodGet || hc.Request.Method == http.MethodHead {
+		status = http.StatusFound
+	}
+	return hc.Redirect(status, url)
+}
+
+// writeResponse - sets the content type (if not empty) and writes the status code
+// and body. returns ErrResponseWritten if the headers have already been written
+func (hc *HandlerContext) writeResponse(status int, contentType string, body []byte) error {
+	if hc.responseWritten() {
+		return ErrResponseWritten
+	}
+	if contentType != "" {
+		hc.ResponseWriter.Header().Set("Content-Type", contentType)
+	}
+	hc.ResponseWriter.WriteHeader(status)
+	if len(body) == 0 {
+		return nil
+	}
+	_, writeErr := hc.ResponseWriter.Write(body)
+	return writeErr
+}
+
+// responseWritten - returns true if the response headers have been written
+func (hc *HandlerContext) responseWritten() bool {
+	checkedWriter, ok := hc.ResponseWriter.(*CheckedResponseWriter)
+	return ok && checkedWriter.HeaderWritten()
+}
+
+// contentTypeWithCharset - adds the utf-8 charset to JSON, XML and text media types
+func contentTypeWithCharset(mediaType string) string {
+	switch mediaType {
+	case MIMEApplicationJSON, MIMEApplicationXML, MIMETextPlain, MIMETextHTML:
+		return mediaType + "; charset=utf-8"
+	}
+	return mediaType
+}
+
+// removeString - returns the list without any items equal to the value
+func removeString(list []string, value string) []string {
+	filtered := list[:0]
+	for _, item := range list {
+		if item != value {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
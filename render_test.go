@@ -0,0 +1,112 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro_test
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/theyakka/goro"
+)
+
+type renderedUser struct {
+	Name string `json:"name" xml:"name"`
+}
+
+func TestRenderHelpers(t *testing.T) {
+	var renderErr error
+	renderRouter := goro.NewRouter()
+	renderRouter.SetTemplates(template.Must(template.New("user").Parse("<p>{{.Name}}</p>")))
+	renderRouter.SetEncoder("text/csv", goro.EncoderFunc(func(w io.Writer, v interface{}) error {
+		_, writeErr := fmt.Fprintf(w, "name\n%s\n", v.(renderedUser).Name)
+		return writeErr
+	}))
+	renderRouter.SetEncoder("text/TSV", goro.EncoderFunc(func(w io.Writer, v interface{}) error {
+		_, writeErr := fmt.Fprintf(w, "name\t%s\n", v.(renderedUser).Name)
+		return writeErr
+	}))
+	user := renderedUser{Name: "bob"}
+	renderers := map[string]goro.ContextHandlerFunc{
+		"/json": func(ctx *goro.HandlerContext) { renderErr = ctx.JSON(http.StatusOK, user) },
+		"/xml":  func(ctx *goro.HandlerContext) { renderErr = ctx.XML(http.StatusOK, user) },
+		"/text": func(ctx *goro.HandlerContext) { renderErr = ctx.Text(http.StatusAccepted, "hello") },
+		"/html": func(ctx *goro.HandlerContext) { renderErr = ctx.HTML(http.StatusOK, "user", user) },
+		"/csv":  func(ctx *goro.HandlerContext) { renderErr = ctx.Render(http.StatusOK, "text/csv", user) },
+		"/tsv":  func(ctx *goro.HandlerContext) { renderErr = ctx.Render(http.StatusOK, "text/TSV", user) },
+		"/blob": func(ctx *goro.HandlerContext) { renderErr = ctx.Blob(http.StatusOK, "image/png", []byte{0x89}) },
+		"/none": func(ctx *goro.HandlerContext) { renderErr = ctx.NoContent(http.StatusNoContent) },
+		"/twice": func(ctx *goro.HandlerContext) {
+			ctx.Text(http.StatusOK, "first")
+			renderErr = ctx.JSON(http.StatusOK, user)
+		},
+		"/redirect": func(ctx *goro.HandlerContext) {
+			renderErr = ctx.Redirect(http.StatusTemporaryRedirect, "/elsewhere")
+		},
+		"/route": func(ctx *goro.HandlerContext) {
+			renderErr = ctx.RedirectToRoute("user", goro.P{"id": 7})
+		},
+	}
+	for routePath, handler := range renderers {
+		renderRouter.GET(routePath).HandleFunc(handler)
+	}
+	renderRouter.GET("/users/:id").Name("user")
+
+	expectations := []struct {
+		path        string
+		status      int
+		contentType string
+		body        string
+	}{
+		{"/json", http.StatusOK, "application/json; charset=utf-8", "{\"name\":\"bob\"}\n"},
+		{"/xml", http.StatusOK, "application/xml; charset=utf-8", "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<renderedUser><name>bob</name></renderedUser>"},
+		{"/text", http.StatusAccepted, "text/plain; charset=utf-8", "hello"},
+		{"/html", http.StatusOK, "text/html; charset=utf-8", "<p>bob</p>"},
+		{"/csv", http.StatusOK, "text/csv", "name\nbob\n"},
+		{"/tsv", http.StatusOK, "text/TSV", "name\tbob\n"},
+		{"/blob", http.StatusOK, "image/png", "\x89"},
+		{"/none", http.StatusNoContent, "", ""},
+		{"/twice", http.StatusOK, "text/plain; charset=utf-8", "first"},
+	}
+	for _, expected := range expectations {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", expected.path, nil)
+		renderRouter.ServeHTTP(recorder, req)
+		if recorder.Code != expected.status || recorder.Header().Get("Content-Type") != expected.contentType ||
+			recorder.Body.String() != expected.body {
+			t.Error("Unexpected response for", expected.path, recorder.Code,
+				recorder.Header().Get("Content-Type"), recorder.Body.String())
+		}
+		if expected.path == "/twice" && renderErr != goro.ErrResponseWritten {
+			t.Error("Expected ErrResponseWritten for the second write but got", renderErr)
+		} else if expected.path != "/twice" && renderErr != nil {
+			t.Error("Expected no error for", expected.path, "but got", renderErr)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/redirect", nil)
+	renderRouter.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusTemporaryRedirect || recorder.Header().Get("Location") != "/elsewhere" {
+		t.Error("Expected a redirect to /elsewhere but got", recorder.Code, recorder.Header().Get("Location"))
+	}
+	recorder = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/route", nil)
+	renderRouter.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusFound || recorder.Header().Get("Location") != "/users/7" {
+		t.Error("Expected a redirect to /users/7 but got", recorder.Code, recorder.Header().Get("Location"))
+	}
+	if renderErr := goro.NewHandlerContext(nil, httptest.NewRecorder(), nil).Redirect(http.StatusOK, "/"); renderErr == nil {
+		t.Error("Expected an error for a non-redirect status code")
+	}
+}
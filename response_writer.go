@@ -16,6 +16,8 @@ import (
 type CheckedResponseWriter struct {
 	http.ResponseWriter
 	headerWritten bool
+	// discardBody - if true, the body will not be written (e.g.: HEAD requests)
+	discardBody bool
 }
 
 func NewCheckedResponseWriter(w http.ResponseWriter) *CheckedResponseWriter {
@@ -30,10 +32,18 @@ func (w *CheckedResponseWriter) WriteHeader(status int) {
 	w.headerWritten = true
 }
 
+// HeaderWritten returns true if the response status / headers have been written
+func (w *CheckedResponseWriter) HeaderWritten() bool {
+	return w.headerWritten
+}
+
 func (w *CheckedResponseWriter) Write(b []byte) (int, error) {
 	if !w.headerWritten {
 		w.WriteHeader(http.StatusOK)
 		w.headerWritten = true
 	}
+	if w.discardBody {
+		return len(b), nil
+	}
 	return w.ResponseWriter.Write(b)
 }
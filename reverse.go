@@ -0,0 +1,167 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// P - parameter values used when generating URLs for named routes
+type P map[string]interface{}
+
+// URL generates the path for a named route. Variables are resolved using the
+// Router variables, wildcards and catch-alls are filled using the supplied
+// parameters. An error is returned if the route does not exist, a parameter is
+// missing or invalid, or if parameters were supplied that the route does not use.
+func (r *Router) URL(name string, params P) (string, error) {
+	route := r.namedRoute(name)
+	if route == nil {
+		return "", fmt.Errorf("no route named '%s'", name)
+	}
+	if route.IsRoot() {
+		if len(params) > 0 {
+			return "", unusedParamsError(name, params, map[string]bool{})
+		}
+		return RootPath, nil
+	}
+	usedParams := map[string]bool{}
+	var builtParts []string
+	deslashedPath := strings.TrimPrefix(route.PathFormat, "/")
+	for _, component := range strings.Split(deslashedPath, "/") {
+		if isVariablePart(component) {
+			resolved, resolveErr := resolveVariableComponent(component, r.variablesForRoute(route))
+			if resolveErr != nil {
+				return "", fmt.Errorf("%s. route='%s'", resolveErr, name)
+			}
+			builtParts = append(builtParts, strings.TrimPrefix(resolved, "/"))
+		} else if isPatternPart(component) {
+			builtPart, buildErr := buildPatternPart(component, params, usedParams)
+			if buildErr != nil {
+				return "", fmt.Errorf("%s. route='%s'", buildErr, name)
+			}
+			builtParts = append(builtParts, builtPart)
+		} else if isWildcardPart(component) {
+			paramName, typeName, constraint := splitWildcardPart(component)
+			value, hasValue := params[paramName]
+			if !hasValue && isOptionalPart(component) {
+				// optional parts are left out of the path
+				continue
+			}
+			if !hasValue {
+				return "", fmt.Errorf("missing parameter '%s'. route='%s'", paramName, name)
+			}
+			stringValue := urlParamString(value)
+			paramType, _ := ParamTypeForName(typeName)
+			if _, ok := paramType.Convert(stringValue); !ok || stringValue == "" ||
+				!matchesConstraint(constraint, stringValue) {
+				return "", fmt.Errorf("invalid value '%s' for parameter '%s'. route='%s'",
+					stringValue, paramName, name)
+			}
+			usedParams[paramName] = true
+			builtParts = append(builtParts, url.PathEscape(stringValue))
+		} else if isCatchAllPart(component) {
+			paramName := catchAllParamName(component)
+			value, hasValue := params[paramName]
+			if !hasValue {
+				return "", fmt.Errorf("missing parameter '%s'. route='%s'", paramName, name)
+			}
+			usedParams[paramName] = true
+			var segments []string
+			if stringSegments, isSlice := value.([]string); isSlice {
+				segments = stringSegments
+			} else {
+				segments = strings.Split(strings.TrimPrefix(urlParamString(value), "/"), "/")
+			}
+			for _, segment := range segments {
+				builtParts = append(builtParts, url.PathEscape(segment))
+			}
+		} else {
+			builtParts = append(builtParts, component)
+		}
+	}
+	if len(usedParams) != len(params) {
+		return "", unusedParamsError(name, params, usedParams)
+	}
+	return "/" + strings.Join(builtParts, "/"), nil
+}
+
+// matchesConstraint - returns true if the value satisfies the regular expression
+// constraint of a wildcard part (or the part has no constraint)
+func matchesConstraint(constraint string, value string) bool {
+	if constraint == "" {
+		return true
+	}
+	compiled, compileErr := regexp.Compile("^(?:" + constraint + ")$")
+	return compileErr == nil && compiled.MatchString(value)
+}
+
+// catchAllParamName - returns the parameter name used for a catch-all part. Unnamed
+// catch-alls use '*' (or '+')
+func catchAllParamName(part string) string {
+	if part == "*" || part == "+" {
+		return part
+	}
+	return strings.TrimLeft(part, "*+")
+}
+
+// buildPatternPart - fills the wildcards of a pattern part (e.g.: ':name.:ext')
+// using the parameters. Values, other than the first, cannot contain the fixed text
+// that precedes them as the path would not match the same values
+func buildPatternPart(part string, params P, usedParams map[string]bool) (string, error) {
+	pattern, parseErr := parseSegmentPattern(part)
+	if parseErr != nil {
+		return "", parseErr
+	}
+	built := pattern.literals[0]
+	for nameIdx, paramName := range pattern.names {
+		value, hasValue := params[paramName]
+		if !hasValue {
+			return "", fmt.Errorf("missing parameter '%s'", paramName)
+		}
+		stringValue := urlParamString(value)
+		if stringValue == "" || (nameIdx > 0 && strings.Contains(stringValue, pattern.literals[nameIdx])) {
+			return "", fmt.Errorf("invalid value '%s' for parameter '%s'", stringValue, paramName)
+		}
+		usedParams[paramName] = true
+		built += url.PathEscape(stringValue) + pattern.literals[nameIdx+1]
+	}
+	return built, nil
+}
+
+// urlParamString - converts a parameter value to its string representation
+func urlParamString(value interface{}) string {
+	switch typedValue := value.(type) {
+	case string:
+		return typedValue
+	case time.Time:
+		return typedValue.Format(time.RFC3339)
+	case fmt.Stringer:
+		return typedValue.String()
+	}
+	return fmt.Sprint(value)
+}
+
+// unusedParamsError - generates an error listing the parameters that were supplied
+// but not used by the route
+func unusedParamsError(name string, params P, usedParams map[string]bool) error {
+	var unused []string
+	for key := range params {
+		if !usedParams[key] {
+			unused = append(unused, key)
+		}
+	}
+	sort.Strings(unused)
+	return fmt.Errorf("unknown parameters '%s'. route='%s'", strings.Join(unused, ", "), name)
+}
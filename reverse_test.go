@@ -0,0 +1,92 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro_test
+
+import (
+	"testing"
+
+	"github.com/theyakka/goro"
+)
+
+func TestRouteURL(t *testing.T) {
+	expectURL(t, "user.show", goro.P{"id": 42}, "/users/42")
+	expectURL(t, "user.action", goro.P{"id": "a b", "action": "call"}, "/users/a%20b/action/call")
+	expectURL(t, "color", nil, "/colors/blue")
+	expectURL(t, "assets", goro.P{"path": "css/site.css"}, "/assets/css/site.css")
+	expectURL(t, "assets", goro.P{"path": []string{"img", "a b.png"}}, "/assets/img/a%20b.png")
+	expectURL(t, "api.v1.stats", nil, "/api/v1/stats")
+}
+
+func TestRouteURLErrors(t *testing.T) {
+	expectURLError(t, "user.missing", nil)
+	expectURLError(t, "user.show", nil)
+	expectURLError(t, "user.show", goro.P{"id": 42, "extra": true})
+}
+
+func TestTypedRouteURL(t *testing.T) {
+	typedRouter := goro.NewRouter()
+	typedRouter.GET("/orders/:id<int>").HandleFunc(testHandler).Name("order")
+	url, urlErr := typedRouter.URL("order", goro.P{"id": 7})
+	if urlErr != nil || url != "/orders/7" {
+		t.Error("Expected '/orders/7' but got", url, urlErr)
+	}
+	if _, urlErr := typedRouter.URL("order", goro.P{"id": "seven"}); urlErr == nil {
+		t.Error("Expected an error for a non-integer id")
+	}
+	typedRouter.GET("/products/:id(^[0-9]+$)").HandleFunc(testHandler).Name("product")
+	if url, urlErr := typedRouter.URL("product", goro.P{"id": 12}); urlErr != nil || url != "/products/12" {
+		t.Error("Expected '/products/12' but got", url, urlErr)
+	}
+	if url, urlErr := typedRouter.URL("product", goro.P{"id": "abc"}); urlErr == nil {
+		t.Error("Expected an error for an id that doesn't match the constraint but got", url)
+	}
+}
+
+func TestDuplicateRouteName(t *testing.T) {
+	nameRouter := goro.NewRouter()
+	nameRouter.GET("/orders/:id").HandleFunc(testHandler).Name("order")
+	nameRouter.GET("/legacy/orders/:id").HandleFunc(testHandler).Name("order")
+	errs := nameRouter.Validate()
+	if len(errs) != 1 || errs[0].Code != goro.RegistrationErrorDuplicateName || errs[0].Path != "/legacy/orders/:id" {
+		t.Error("Expected a duplicate name error but got", errs)
+	}
+
+	strictRouter := goro.NewRouter()
+	strictRouter.SetStrictRegistration(true)
+	strictRouter.GET("/orders/:id").HandleFunc(testHandler).Name("order")
+	func() {
+		defer func() {
+			if _, ok := recover().(goro.RegistrationError); !ok {
+				t.Error("Expected a RegistrationError panic for a duplicate name")
+			}
+		}()
+		strictRouter.GET("/legacy/orders/:id").HandleFunc(testHandler).Name("order")
+	}()
+	if url, urlErr := strictRouter.URL("order", goro.P{"id": 1}); urlErr != nil || url != "/orders/1" {
+		t.Error("Expected the name to keep referring to the first route but got", url, urlErr)
+	}
+}
+
+func expectURL(t *testing.T, name string, params goro.P, expected string) {
+	url, urlErr := router.URL(name, params)
+	if urlErr != nil {
+		t.Error("Expected URL for", name, "but got error", urlErr)
+		return
+	}
+	if url != expected {
+		t.Error("Expected URL", expected, "but got", url)
+	}
+}
+
+func expectURLError(t *testing.T, name string, params goro.P) {
+	if url, urlErr := router.URL(name, params); urlErr == nil {
+		t.Error("Expected an error for", name, "but got", url)
+	}
+}
@@ -10,7 +10,9 @@
 package goro
 
 import (
+	"net/http"
 	"strings"
+	"sync/atomic"
 )
 
 const (
@@ -19,16 +21,60 @@ const (
 
 	// RouteInfoKeyDescription - does the route have a catch all part
 	RouteInfoKeyDescription string = "description"
+
+	// RouteInfoKeyName - the fully qualified name of the route (used for URL generation)
+	RouteInfoKeyName string = "name"
 )
 
 // Route stores all the information about a route
 type Route struct {
+	// disabled - 1 if the route has been disabled (accessed atomically)
+	disabled int32
+
 	Method     string
 	Path       string
 	PathFormat string
 	Handler    ContextHandler
 	Meta       map[string]interface{}
 	Info       map[string]interface{}
+
+	// router - the router the route has been registered with (if any)
+	router *Router
+
+	// namePrefix - the prefix applied to the route name (e.g.: the group name)
+	namePrefix string
+
+	// sourceFile / sourceLine - where the route was created (for error reporting)
+	sourceFile string
+	sourceLine int
+
+	// group - the group the route was created in (if any)
+	group *Group
+
+	// filters - filters that only apply to this route (e.g.: bundle filters)
+	filters []HaltingFilter
+
+	// errorHandlers - status code specific error handlers that only apply to this
+	// route. these take precedence over the Router error handlers
+	errorHandlers map[int]ContextHandler
+
+	// variables - variables that only apply to this route. these take precedence
+	// over the Router variables
+	variables map[string]string
+
+	// mount - the handler mounted at the route (if the route was created by Mount)
+	mount http.Handler
+
+	// conditions - request attributes required for the route to be selected
+	conditions routeConditions
+
+	// priority - overrides the default precedence when matching (higher first).
+	// accessed atomically as it can be changed while requests are being matched
+	priority int32
+
+	// methods - all of the methods the route is registered for (see Router.Match).
+	// nil if the route is only registered for Method
+	methods []string
 }
 
 // NewRoute creates a new Route instance
@@ -53,9 +99,35 @@ func NewRouteWithMeta(method string, path string, meta map[string]interface{}) *
 		info[RouteInfoKeyIsRoot] = true
 	}
 	route.Info = info
+	route.sourceFile, route.sourceLine = callerLocation()
 	return route
 }
 
+// newRouteForMethods creates a new Route instance that is registered for each of the
+// methods. The first method is used as the Route Method
+func newRouteForMethods(methods []string, path string) *Route {
+	firstMethod := ""
+	if len(methods) > 0 {
+		firstMethod = methods[0]
+	}
+	route := NewRoute(firstMethod, path)
+	for _, method := range methods {
+		upMethod := strings.ToUpper(method)
+		if !containsString(route.methods, upMethod) {
+			route.methods = append(route.methods, upMethod)
+		}
+	}
+	return route
+}
+
+// Methods returns all of the methods that the Route is registered for
+func (rte *Route) Methods() []string {
+	if len(rte.methods) == 0 {
+		return []string{rte.Method}
+	}
+	return rte.methods
+}
+
 // Handle adds a ContextHandler to the Route
 func (rte *Route) Handle(handlerFunc ContextHandler) *Route {
 	rte.Handler = handlerFunc
@@ -74,6 +146,127 @@ func (rte *Route) Describe(description string) *Route {
 	return rte
 }
 
+// Name assigns a name to the Route so that URLs can be generated for it using
+// Router.URL. If the route belongs to a Group, the group name will be prepended
+// (e.g.: 'api.v1.stats')
+func (rte *Route) Name(name string) *Route {
+	fullName := name
+	if rte.namePrefix != "" {
+		fullName = rte.namePrefix + "." + name
+	}
+	rte.Info[RouteInfoKeyName] = fullName
+	if rte.router != nil {
+		rte.router.setNamedRoute(fullName, rte)
+	}
+	return rte
+}
+
+// Disable stops the Route from being matched until Enable is called. The Route
+// remains registered (so it can still be used to generate URLs)
+func (rte *Route) Disable() *Route {
+	atomic.StoreInt32(&rte.disabled, 1)
+	if rte.router != nil {
+		rte.router.refreshTable()
+	}
+	return rte
+}
+
+// Enable allows a disabled Route to be matched again
+func (rte *Route) Enable() *Route {
+	atomic.StoreInt32(&rte.disabled, 0)
+	if rte.router != nil {
+		rte.router.refreshTable()
+	}
+	return rte
+}
+
+// IsDisabled returns true if the Route has been disabled
+func (rte *Route) IsDisabled() bool {
+	return atomic.LoadInt32(&rte.disabled) == 1
+}
+
+// Priority overrides the default matching precedence for the Route. When several
+// routes could match a path, the parts leading to routes with a higher priority are
+// checked first (regardless of whether they are fixed, wildcard or catch-all parts).
+// The default priority is zero; negative values lower the precedence
+func (rte *Route) Priority(priority int) *Route {
+	atomic.StoreInt32(&rte.priority, int32(priority))
+	if rte.router != nil {
+		rte.router.refreshTable()
+	}
+	return rte
+}
+
+// matchPriority - returns the priority of the Route (see Priority)
+func (rte *Route) matchPriority() int {
+	return int(atomic.LoadInt32(&rte.priority))
+}
+
+// Filter adds filters that will only be executed for this Route
+func (rte *Route) Filter(filters ...Filter) *Route {
+	rte.filters = append(rte.filters, adaptFilters(filters)...)
+	return rte
+}
+
+// HaltingFilter adds filters, that can stop the request from being dispatched, that
+// will only be executed for this Route
+func (rte *Route) HaltingFilter(filters ...HaltingFilter) *Route {
+	rte.filters = append(rte.filters, filters...)
+	return rte
+}
+
+// Filters returns the filters that apply to the Route (excluding the Router filters),
+// in execution order. Group filters are followed by the Route filters
+func (rte *Route) Filters() []HaltingFilter {
+	if rte.group == nil {
+		return rte.filters
+	}
+	filters := rte.group.Filters()
+	if len(rte.filters) == 0 {
+		return filters
+	}
+	return append(append([]HaltingFilter{}, filters...), rte.filters...)
+}
+
+// copyWithPath returns a copy of the Route using a different path format
+func (rte *Route) copyWithPath(routePath string) *Route {
+	meta := make(map[string]interface{}, len(rte.Meta))
+	for key, value := range rte.Meta {
+		meta[key] = value
+	}
+	route := NewRouteWithMeta(rte.Method, routePath, meta)
+	for key, value := range rte.Info {
+		if key != RouteInfoKeyIsRoot {
+			route.Info[key] = value
+		}
+	}
+	route.Handler = rte.Handler
+	route.namePrefix = rte.namePrefix
+	route.group = rte.group
+	route.sourceFile = rte.sourceFile
+	route.sourceLine = rte.sourceLine
+	route.filters = rte.filters
+	route.errorHandlers = rte.errorHandlers
+	route.variables = rte.variables
+	route.mount = rte.mount
+	route.conditions = rte.conditions
+	route.priority = atomic.LoadInt32(&rte.priority)
+	route.methods = rte.methods
+	return route
+}
+
+// copyForRouter returns a copy of the Route (including its disabled state) that is
+// registered with the router
+func (rte *Route) copyForRouter(router *Router) *Route {
+	route := rte.copyWithPath(rte.PathFormat)
+	route.Path = rte.Path
+	route.router = router
+	if rte.IsDisabled() {
+		route.disabled = 1
+	}
+	return route
+}
+
 // IsRoot returns true if the Route path is '/'
 func (rte *Route) IsRoot() bool {
 	return rte.Info[RouteInfoKeyIsRoot] == true
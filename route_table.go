@@ -0,0 +1,396 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// routeTable - an immutable snapshot of the registered routes. Routes are added to
+// and removed from the working tree of the Router. The first time the routes are
+// used after a change, a copy of the working tree is published as a new table so
+// that requests can be matched without locking (and so that registering a batch of
+// routes only copies the tree once)
+type routeTable struct {
+	// tree - the routes tree. must not be modified once the table is published
+	tree *Tree
+
+	// index - the compiled form of the tree used for matching. built on first use
+	index     *routeIndex
+	indexOnce sync.Once
+}
+
+// routeIndex - returns the compiled route index, building it if required
+func (rt *routeTable) routeIndex() *routeIndex {
+	rt.indexOnce.Do(func() {
+		rt.index = newRouteIndex(rt.tree)
+	})
+	return rt.index
+}
+
+// currentTable - returns the route table that requests are currently matched
+// against, publishing any changes to the routes first
+func (r *Router) currentTable() *routeTable {
+	if atomic.LoadInt32(&r.tableChanged) == 1 {
+		r.publishChanges()
+	}
+	return r.table.Load().(*routeTable)
+}
+
+// publishChanges - publishes a copy of the working tree if the routes have changed
+// since the last table was published
+func (r *Router) publishChanges() {
+	r.tableMutex.Lock()
+	defer r.tableMutex.Unlock()
+	if atomic.LoadInt32(&r.tableChanged) == 0 {
+		return
+	}
+	// the route cache was cleared when the working tree was changed
+	r.table.Store(&routeTable{tree: r.workingTree.Clone()})
+	atomic.StoreInt32(&r.tableChanged, 0)
+}
+
+// routeIndex - returns the compiled route index for the current route table
+func (r *Router) routeIndex() *routeIndex {
+	return r.currentTable().routeIndex()
+}
+
+// publishTable - replaces the route table and invalidates the route cache. the
+// table mutex must be held by the caller
+func (r *Router) publishTable(tree *Tree) {
+	r.table.Store(&routeTable{tree: tree})
+	r.cache.Clear()
+}
+
+// updateTable - applies the update to the working tree. the changes are published
+// when the routes are next used. update should return false if it made no changes
+func (r *Router) updateTable(update func(tree *Tree) bool) {
+	r.tableMutex.Lock()
+	defer r.tableMutex.Unlock()
+	wasChanged := atomic.LoadInt32(&r.tableChanged)
+	// the table is marked as changed (and the cache cleared) before updating so that
+	// requests never cache a match against the routes that are being replaced
+	atomic.StoreInt32(&r.tableChanged, 1)
+	r.cache.Clear()
+	if !update(r.workingTree) {
+		atomic.StoreInt32(&r.tableChanged, wasChanged)
+	}
+}
+
+// Tree - returns the routes tree that requests are currently matched against. The
+// tree must be treated as read-only; use Tree.Clone to create a modifiable copy
+func (r *Router) Tree() *Tree {
+	return r.currentTable().tree
+}
+
+// Swap - atomically replaces all of the routes with those in the tree and returns
+// the previous tree. Requests that are being handled will complete using the routes
+// that they were matched against. The tree must not be modified after it is swapped
+// in. Named routes are replaced with the named routes from the tree. If the tree
+// contains routes that are registered with another Router (e.g.: the tree of a
+// staging Router), the tree is copied and those routes are replaced with copies so
+// that the other Router is not affected
+func (r *Router) Swap(tree *Tree) *Tree {
+	r.tableMutex.Lock()
+	defer r.tableMutex.Unlock()
+	previous := r.workingTree
+	tree = r.adoptTree(tree)
+	namedRoutes := map[string]*Route{}
+	tree.walkRoutes(func(route *Route) {
+		if routeName, ok := route.Info[RouteInfoKeyName].(string); ok && routeName != "" {
+			namedRoutes[routeName] = route
+		}
+	})
+	r.namedRoutesMutex.Lock()
+	r.namedRoutes = namedRoutes
+	r.namedRoutesMutex.Unlock()
+	// the swapped in tree is published as is and changes are made to a copy
+	r.workingTree = tree.Clone()
+	r.publishTable(tree)
+	atomic.StoreInt32(&r.tableChanged, 0)
+	return previous
+}
+
+// adoptTree - registers the routes in the tree with the router. if any of the routes
+// are registered with another router, a copy of the tree is returned in which those
+// routes have been replaced with copies
+func (r *Router) adoptTree(tree *Tree) *Tree {
+	foreign := false
+	tree.walkRoutes(func(route *Route) {
+		if route.router != nil && route.router != r {
+			foreign = true
+		}
+	})
+	if foreign {
+		tree = tree.Clone()
+	}
+	// routes with optional parts are stored at several nodes and share one copy
+	copies := map[*Route]*Route{}
+	var adoptNodes func(nodes []*Node)
+	adoptNodes = func(nodes []*Node) {
+		for _, node := range nodes {
+			for _, methodRoutes := range node.routes {
+				for idx, route := range methodRoutes {
+					if route.router == nil || route.router == r {
+						route.router = r
+						continue
+					}
+					if copies[route] == nil {
+						copies[route] = route.copyForRouter(r)
+					}
+					methodRoutes[idx] = copies[route]
+				}
+			}
+			adoptNodes(node.nodes)
+		}
+	}
+	adoptNodes(tree.nodes)
+	return tree
+}
+
+// Remove - removes the routes registered for the method and path format (e.g.:
+// '/users/:id'), including any routes with request conditions. Returns false if
+// no such route was registered
+func (r *Router) Remove(method string, routePath string) bool {
+	var removed []*Route
+	// routes registered for several methods remain registered for the other methods
+	remaining := map[*Route]bool{}
+	r.updateTable(func(tree *Tree) bool {
+		removed = tree.removeRoutes(NewRoute(method, routePath), r.variables)
+		tree.walkRoutes(func(route *Route) {
+			remaining[route] = true
+		})
+		return len(removed) > 0
+	})
+	r.namedRoutesMutex.Lock()
+	defer r.namedRoutesMutex.Unlock()
+	for _, route := range removed {
+		if remaining[route] {
+			continue
+		}
+		if routeName, ok := route.Info[RouteInfoKeyName].(string); ok && r.namedRoutes[routeName] == route {
+			delete(r.namedRoutes, routeName)
+		}
+	}
+	return len(removed) > 0
+}
+
+// refreshTable - republishes the current tree so that changes to the routes that
+// are reflected in the compiled index (e.g.: disabling a route) take effect
+func (r *Router) refreshTable() {
+	r.tableMutex.Lock()
+	defer r.tableMutex.Unlock()
+	if atomic.LoadInt32(&r.tableChanged) == 1 {
+		return // the index is rebuilt when the changes are published
+	}
+	r.publishTable(r.table.Load().(*routeTable).tree)
+}
+
+// setNamedRoute - registers the route with the name so it can be used with URL. if
+// another route already has the name, a RegistrationError is recorded (with strict
+// registration it is raised and the name keeps referring to the other route)
+func (r *Router) setNamedRoute(name string, route *Route) {
+	r.namedRoutesMutex.Lock()
+	defer r.namedRoutesMutex.Unlock()
+	if existing := r.namedRoutes[name]; existing != nil && existing != route {
+		r.recordRegistrationError(NewRegistrationError(RegistrationErrorDuplicateName, route,
+			fmt.Sprintf("route name '%s' is already used by the route at %s:%d", name,
+				existing.sourceFile, existing.sourceLine)))
+	}
+	r.namedRoutes[name] = route
+}
+
+// namedRoute - returns the route registered with the name (or nil)
+func (r *Router) namedRoute(name string) *Route {
+	r.namedRoutesMutex.RLock()
+	defer r.namedRoutesMutex.RUnlock()
+	return r.namedRoutes[name]
+}
+
+// Clone - returns a deep copy of the tree. Routes are shared between the trees
+func (t *Tree) Clone() *Tree {
+	return &Tree{
+		nodes: cloneNodes(t.nodes, nil),
+	}
+}
+
+// cloneNodes - copies the nodes (and all of their children) attaching them to parent
+func cloneNodes(nodes []*Node, parent *Node) []*Node {
+	cloned := make([]*Node, len(nodes))
+	for idx, node := range nodes {
+		nodeCopy := *node
+		nodeCopy.parent = parent
+		if node.routes != nil {
+			nodeCopy.routes = make(map[string][]*Route, len(node.routes))
+			for method, methodRoutes := range node.routes {
+				nodeCopy.routes[method] = append([]*Route{}, methodRoutes...)
+			}
+		}
+		nodeCopy.nodes = cloneNodes(node.nodes, &nodeCopy)
+		cloned[idx] = &nodeCopy
+	}
+	return cloned
+}
+
+// allRoutes - returns all of the routes registered at the node, ordered by method
+// and then by registration order. Routes registered for several methods are only
+// included once
+func (node *Node) allRoutes() []*Route {
+	var routes []*Route
+	for _, method := range node.methods() {
+		for _, route := range node.routes[method] {
+			if !containsRoute(routes, route) {
+				routes = append(routes, route)
+			}
+		}
+	}
+	return routes
+}
+
+// methods - returns the (sorted) methods that routes are registered for at the node
+func (node *Node) methods() []string {
+	methods := make([]string, 0, len(node.routes))
+	for method := range node.routes {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// walkRoutes - calls fn once for every route in the tree (routes with optional
+// parts are stored at more than one node)
+func (t *Tree) walkRoutes(fn func(route *Route)) {
+	seen := map[*Route]bool{}
+	var walkNodes func(nodes []*Node)
+	walkNodes = func(nodes []*Node) {
+		for _, node := range nodes {
+			for _, route := range node.allRoutes() {
+				if !seen[route] {
+					seen[route] = true
+					fn(route)
+				}
+			}
+			walkNodes(node.nodes)
+		}
+	}
+	walkNodes(t.nodes)
+}
+
+// nodesForRoute - returns the nodes that the route is registered at
+func (t *Tree) nodesForRoute(route *Route) []*Node {
+	var found []*Node
+	var walkNodes func(nodes []*Node)
+	walkNodes = func(nodes []*Node) {
+		for _, node := range nodes {
+			if containsRoute(node.allRoutes(), route) {
+				found = append(found, node)
+			}
+			walkNodes(node.nodes)
+		}
+	}
+	walkNodes(t.nodes)
+	return found
+}
+
+// removeRoutes - removes the routes registered for the method and path of the route
+// from the tree (at every node that the optional parts expand to). Any nodes left
+// without routes or children are removed. Returns the removed routes
+func (t *Tree) removeRoutes(route *Route, variables map[string]string) []*Route {
+	split, splitErr := t.splitRoutePath(route, variables)
+	if splitErr != nil {
+		return nil
+	}
+	var removed []*Route
+	for _, parts := range expandOptionalParts(split) {
+		for _, removedRoute := range t.removeRoutesAtParts(route.Method, parts) {
+			if !containsRoute(removed, removedRoute) {
+				removed = append(removed, removedRoute)
+			}
+		}
+	}
+	return removed
+}
+
+// removeRoutesAtParts - removes the routes for the method from the node for the
+// parts, pruning any nodes that are no longer required
+func (t *Tree) removeRoutesAtParts(method string, parts []string) []*Route {
+	var node *Node
+	for _, component := range parts {
+		node = t.nodeForExactPart(component, node)
+		if node == nil {
+			return nil
+		}
+	}
+	removed := node.routes[method]
+	if len(removed) == 0 {
+		return nil
+	}
+	delete(node.routes, method)
+	t.pruneNodes(node)
+	return removed
+}
+
+// detachRoute - removes the route from the nodes that it was added at, pruning any
+// nodes that are no longer required
+func (t *Tree) detachRoute(route *Route, nodes []*Node) {
+	for _, node := range nodes {
+		for method, methodRoutes := range node.routes {
+			remaining := make([]*Route, 0, len(methodRoutes))
+			for _, methodRoute := range methodRoutes {
+				if methodRoute != route {
+					remaining = append(remaining, methodRoute)
+				}
+			}
+			if len(remaining) == 0 {
+				delete(node.routes, method)
+			} else {
+				node.routes[method] = remaining
+			}
+		}
+		t.pruneNodes(node)
+	}
+}
+
+// pruneNodes - removes the node, and then each of its parents, while they have no
+// routes or children
+func (t *Tree) pruneNodes(node *Node) {
+	for node != nil && len(node.routes) == 0 && !node.HasChildren() {
+		parent := node.parent
+		if parent == nil {
+			t.nodes = removeNode(t.nodes, node)
+		} else {
+			parent.nodes = removeNode(parent.nodes, node)
+		}
+		node = parent
+	}
+}
+
+// containsRoute - returns true if the route is in the routes
+func containsRoute(routes []*Route, route *Route) bool {
+	for _, candidate := range routes {
+		if candidate == route {
+			return true
+		}
+	}
+	return false
+}
+
+// removeNode - returns the nodes without the node
+func removeNode(nodes []*Node, node *Node) []*Node {
+	for idx, candidate := range nodes {
+		if candidate == node {
+			return append(nodes[:idx:idx], nodes[idx+1:]...)
+		}
+	}
+	return nodes
+}
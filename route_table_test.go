@@ -0,0 +1,143 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/theyakka/goro"
+)
+
+func statusFor(handler http.Handler, method string, path string) int {
+	req, _ := http.NewRequest(method, path, nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRemoveRoute(t *testing.T) {
+	removeRouter := goro.NewRouter()
+	removeRouter.GET("/users/:id").HandleFunc(testHandler).Name("user")
+	removeRouter.GET("/users/:id/posts").HandleFunc(testHandler)
+	removeRouter.POST("/users/:id").HandleFunc(testHandler)
+
+	expectHitResult(t, removeRouter, "GET", "/users/42")
+	if !removeRouter.Remove("GET", "/users/:id") {
+		t.Error("Expected the route to be removed")
+	}
+	if removeRouter.Remove("GET", "/users/:id") {
+		t.Error("Expected the route to already be removed")
+	}
+	if status := statusFor(removeRouter, "GET", "/users/42"); status != http.StatusMethodNotAllowed {
+		t.Error("Expected 405 for the removed route but got", status)
+	}
+	if _, urlErr := removeRouter.URL("user", goro.P{"id": 42}); urlErr == nil {
+		t.Error("Expected the route name to be removed")
+	}
+	expectHitResult(t, removeRouter, "GET", "/users/42/posts")
+	expectHitResult(t, removeRouter, "POST", "/users/42")
+
+	removeRouter.Remove("POST", "/users/:id")
+	removeRouter.Remove("GET", "/users/:id/posts")
+	if status := statusFor(removeRouter, "POST", "/users/42"); status != http.StatusNotFound {
+		t.Error("Expected 404 once all routes are removed but got", status)
+	}
+}
+
+func TestDisableRoute(t *testing.T) {
+	disableRouter := goro.NewRouter()
+	specific := disableRouter.GET("/flags/beta").HandleFunc(func(ctx *goro.HandlerContext) {})
+	disableRouter.GET("/flags/:name").HandleFunc(testHandler)
+
+	expectNotHitResult(t, disableRouter, "GET", "/flags/beta")
+	specific.Disable()
+	expectHitResult(t, disableRouter, "GET", "/flags/beta")
+	specific.Enable()
+	expectNotHitResult(t, disableRouter, "GET", "/flags/beta")
+}
+
+func TestSwapRoutes(t *testing.T) {
+	liveRouter := goro.NewRouter()
+	liveRouter.GET("/v1").HandleFunc(testHandler)
+
+	stagingRouter := goro.NewRouter()
+	stagingRouter.GET("/v2").HandleFunc(testHandler).Name("v2")
+	previous := liveRouter.Swap(stagingRouter.Tree().Clone())
+	expectNotHitResult(t, liveRouter, "GET", "/v1")
+	expectHitResult(t, liveRouter, "GET", "/v2")
+	if url, _ := liveRouter.URL("v2", nil); url != "/v2" {
+		t.Error("Expected named routes to be swapped but got", url)
+	}
+
+	liveRouter.Swap(previous)
+	expectHitResult(t, liveRouter, "GET", "/v1")
+	expectNotHitResult(t, liveRouter, "GET", "/v2")
+
+	// swapping in the live tree of another router copies its routes
+	stagingRoute := stagingRouter.GET("/v3").HandleFunc(testHandler).Name("v3")
+	liveRouter.Swap(stagingRouter.Tree())
+	expectHitResult(t, liveRouter, "GET", "/v3")
+	stagingRoute.Disable().Name("beta")
+	expectNotHitResult(t, stagingRouter, "GET", "/v3")
+	expectHitResult(t, liveRouter, "GET", "/v3")
+	expectHitResult(t, stagingRouter, "GET", "/v2")
+	if url, _ := stagingRouter.URL("beta", nil); url != "/v3" {
+		t.Error("Expected the source router named routes to be updated but got", url)
+	}
+	if url, _ := liveRouter.URL("v3", nil); url != "/v3" {
+		t.Error("Expected the swapped router named routes to be unchanged but got", url)
+	}
+	if _, urlErr := liveRouter.URL("beta", nil); urlErr == nil {
+		t.Error("Expected the source router route names not to be added to the swapped router")
+	}
+}
+
+func TestConcurrentRouteChanges(t *testing.T) {
+	concurrentRouter := goro.NewRouter()
+	concurrentRouter.GET("/stable/:id").HandleFunc(func(ctx *goro.HandlerContext) {})
+	stagingRouter := goro.NewRouter()
+	stagingRouter.GET("/stable/:id").HandleFunc(func(ctx *goro.HandlerContext) {})
+
+	var wg sync.WaitGroup
+	errs := make(chan int, 400)
+	for worker := 0; worker < 4; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := 0; idx < 100; idx++ {
+				if status := statusFor(concurrentRouter, "GET", "/stable/1"); status != http.StatusOK {
+					errs <- status
+				}
+				statusFor(concurrentRouter, "GET", "/toggle")
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for idx := 0; idx < 50; idx++ {
+			toggle := concurrentRouter.GET("/toggle").HandleFunc(func(ctx *goro.HandlerContext) {})
+			toggle.Disable()
+			toggle.Enable()
+			toggle.Priority(idx)
+			concurrentRouter.Remove("GET", "/toggle")
+			previous := concurrentRouter.Swap(stagingRouter.Tree())
+			concurrentRouter.Swap(previous)
+		}
+	}()
+	wg.Wait()
+	close(errs)
+	for status := range errs {
+		t.Error("Expected the stable route to always be served but got", status)
+	}
+}
@@ -12,11 +12,15 @@ package goro
 import (
 	"errors"
 	"fmt"
+	"html/template"
 	"net/http"
 	"os"
 	"path/filepath"
 	"runtime/debug"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 // Router is the main routing class
@@ -38,6 +42,20 @@ type Router struct {
 	// mapped http methods do not match the one requested?
 	methodNotAllowedIsError bool
 
+	// autoHead - if true, HEAD requests will be handled by the GET route (discarding
+	// the body) when no HEAD route has been registered
+	autoHead bool
+
+	// autoOptions - if true, OPTIONS requests will be answered with the allowed methods
+	// when no OPTIONS route has been registered
+	autoOptions bool
+
+	// allowHeaderOnError - if true, the Allow header will be set on 405 responses
+	allowHeaderOnError bool
+
+	// pathPolicy - how requests for non-canonical paths are handled
+	pathPolicy PathPolicy
+
 	// BeforeChain - a Chain of handlers that will always be executed before the Route handler
 	//BeforeChain Chain
 
@@ -51,20 +69,58 @@ type Router struct {
 	staticLocations []StaticLocation
 
 	// filters - registered pre-process filters
-	filters []Filter
+	filters []HaltingFilter
 
 	// routeMatcher - the primary route matcher instance
 	routeMatcher *Matcher
 
-	// methodKeyedRoutes - all routes registered with the router
-	routes *Tree
+	// table - the current (immutable) *routeTable. requests are matched against
+	// the table without locking
+	table atomic.Value
+
+	// tableMutex - serializes changes to the route table
+	tableMutex sync.Mutex
+
+	// workingTree - the tree that routes are added to and removed from. a copy is
+	// published as the route table when the routes are next used
+	workingTree *Tree
+
+	// tableChanged - 1 if the working tree has changed since the route table was
+	// published (accessed atomically)
+	tableChanged int32
 
 	// variables - unwrapped (clean) variables that have been defined
 	variables map[string]string
 
+	// namedRoutes - routes that have been assigned a name, keyed by the full name
+	namedRoutes      map[string]*Route
+	namedRoutesMutex sync.RWMutex
+
+	// strictRegistration - if true, registration problems will cause a panic in Use
+	strictRegistration bool
+
+	// registrationErrors - problems that were found when registering routes
+	registrationErrors []RegistrationError
+
+	// validators - custom validation rules (see AddValidator)
+	validators map[string]ValidatorFunc
+
+	// encoders - encoders used to render responses, keyed by media type
+	encoders map[string]Encoder
+
+	// encoderTypes - the media types of the encoders, in order of preference
+	encoderTypes []string
+
+	// templates - templates used to render HTML responses
+	templates *template.Template
+
 	// cache - matched routes to path mappings
 	cache *RouteCache
 
+	// parent - the router this router has been mounted in (if any). error handlers
+	// that are not defined on this router are looked up on the parent
+	parent *Router
+
 	// debugLevel - if enabled will output debugging information
 	debugLevel DebugLevel
 }
@@ -76,15 +132,24 @@ func NewRouter() *Router {
 		ShouldCacheMatchedRoutes: true,
 		alwaysUseFirstMatch:      false,
 		methodNotAllowedIsError:  true,
+		autoHead:                 true,
+		autoOptions:              true,
+		allowHeaderOnError:       true,
+		pathPolicy:               PathPolicyLenient,
 		errorHandlers:            map[int]ContextHandler{},
 		globalHandlers:           map[string]ContextHandler{},
 		staticLocations:          []StaticLocation{},
 		filters:                  nil,
-		routes:                   NewTree(),
 		variables:                map[string]string{},
+		namedRoutes:              map[string]*Route{},
+		validators:               map[string]ValidatorFunc{},
+		encoders:                 defaultEncoders(),
+		encoderTypes:             []string{MIMEApplicationJSON, MIMEApplicationXML},
 		cache:                    NewRouteCache(),
 		debugLevel:               DebugLevelNone,
+		workingTree:              NewTree(),
 	}
+	router.table.Store(&routeTable{tree: NewTree()})
 	matcher := NewMatcher(router)
 	matcher.FallbackToCatchAll = router.alwaysUseFirstMatch == false &&
 		router.methodNotAllowedIsError == false
@@ -123,6 +188,33 @@ func (r *Router) SetMethodNotAllowedIsError(isError bool) {
 		r.methodNotAllowedIsError == false
 }
 
+// SetAutoHead - Will the router handle HEAD requests using the GET route (without
+// writing the body) if no HEAD route has been defined?
+func (r *Router) SetAutoHead(autoHead bool) {
+	r.autoHead = autoHead
+	r.cache.Clear()
+}
+
+// SetAutoOptions - Will the router answer OPTIONS requests with the allowed methods
+// if no OPTIONS route has been defined?
+func (r *Router) SetAutoOptions(autoOptions bool) {
+	r.autoOptions = autoOptions
+	r.cache.Clear()
+}
+
+// SetAllowHeaderOnMethodNotAllowed - Will the router set the Allow header when it
+// emits a 405 (Method Not Allowed) error?
+func (r *Router) SetAllowHeaderOnMethodNotAllowed(allowHeader bool) {
+	r.allowHeaderOnError = allowHeader
+}
+
+// SetCaseSensitive - Should fixed path segments only match if they are in the same
+// case as the route declaration? Parameter names always keep their declared case.
+func (r *Router) SetCaseSensitive(caseSensitive bool) {
+	r.routeMatcher.CaseSensitive = caseSensitive
+	r.cache.Clear()
+}
+
 // NewMatcher returns a new matcher for the given Router
 func (r *Router) NewMatcher() *Matcher {
 	return NewMatcher(r)
@@ -150,10 +242,6 @@ func (r *Router) Add(method string, routePath string) *Route {
 	return r.Use(route)[0]
 }
 
-func (r *Router) AddBundle(bundle Bundle) {
-	//processBundle(bundle)
-}
-
 // Add creates a new Route using the GET method and registers the instance within the Router
 func (r *Router) GET(routePath string) *Route {
 	return r.Add("GET", routePath)
@@ -169,10 +257,69 @@ func (r *Router) PUT(routePath string) *Route {
 	return r.Add("PUT", routePath)
 }
 
-// Use registers one or more Route instances within the Router
+// Add creates a new Route using the DELETE method and registers the instance within the Router
+func (r *Router) DELETE(routePath string) *Route {
+	return r.Add("DELETE", routePath)
+}
+
+// Add creates a new Route using the PATCH method and registers the instance within the Router
+func (r *Router) PATCH(routePath string) *Route {
+	return r.Add("PATCH", routePath)
+}
+
+// Add creates a new Route using the HEAD method and registers the instance within the Router
+func (r *Router) HEAD(routePath string) *Route {
+	return r.Add("HEAD", routePath)
+}
+
+// Add creates a new Route using the OPTIONS method and registers the instance within the Router
+func (r *Router) OPTIONS(routePath string) *Route {
+	return r.Add("OPTIONS", routePath)
+}
+
+// Any creates a new Route that handles all methods and registers the instance within
+// the Router. Routes registered for a specific method take precedence
+func (r *Router) Any(routePath string) *Route {
+	return r.Add(MethodAny, routePath)
+}
+
+// Match creates a single Route that is registered for each of the methods (e.g.:
+// []string{"GET", "POST"}) so that the handler, filters and metadata are shared.
+// Custom methods (e.g.: 'PROPFIND') are supported
+func (r *Router) Match(methods []string, routePath string) *Route {
+	return r.Use(newRouteForMethods(methods, routePath))[0]
+}
+
+// Use registers one or more Route instances within the Router. Any problems with
+// the registration are recorded and can be retrieved using Validate or, if strict
+// registration is enabled, will cause a panic (see SetStrictRegistration). Routes
+// can be registered while the Router is serving requests.
 func (r *Router) Use(routes ...*Route) []*Route {
+	r.updateTable(func(tree *Tree) bool {
+		for _, route := range routes {
+			nodes, addErr := tree.addRoute(route, r.variablesForRoute(route))
+			if addErr != nil {
+				r.recordRegistrationError(addErr)
+				continue
+			}
+			if r.strictRegistration {
+				if pathErrs := validateRoutePath(tree, route, nodes); len(pathErrs) > 0 {
+					tree.detachRoute(route, nodes)
+					panic(pathErrs[0])
+				}
+				if duplicateErr, found := routeDuplicateError(route, nodes); found {
+					tree.detachRoute(route, nodes)
+					panic(duplicateErr)
+				}
+			}
+		}
+		return true
+	})
 	for _, route := range routes {
-		r.routes.AddRouteToTree(route, r.variables)
+		route.router = r
+		if routeName, ok := route.Info[RouteInfoKeyName].(string); ok && routeName != "" {
+			r.setNamedRoute(routeName, route)
+		}
 	}
 	return routes
 }
@@ -210,6 +357,12 @@ func (r *Router) SetErrorHandler(statusCode int, handler ContextHandler) {
 
 // AddFilter adds a filter to the list of pre-process filters
 func (r *Router) AddFilter(filter Filter) {
+	r.filters = append(r.filters, AdaptFilter(filter))
+}
+
+// AddHaltingFilter adds a filter, that can stop the request from being dispatched,
+// to the list of pre-process filters
+func (r *Router) AddHaltingFilter(filter HaltingFilter) {
 	r.filters = append(r.filters, filter)
 }
 
@@ -229,16 +382,29 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	if r.errorHandler != nil {
 		defer r.recoverPanic(hContext)
 	}
+	r.serveContext(hContext)
+}
+
+// serveContext - matches and dispatches the request in the context. used by both
+// ServeHTTP and mounted Routers (which share their parent's context)
+func (r *Router) serveContext(hContext *HandlerContext) {
+	respWriter, isChecked := hContext.ResponseWriter.(*CheckedResponseWriter)
+	if !isChecked {
+		respWriter = NewCheckedResponseWriter(hContext.ResponseWriter)
+		hContext.ResponseWriter = respWriter
+	}
+	req := hContext.Request
 	// execute all the filters
-	if r.filters != nil && len(r.filters) > 0 {
-		for _, filter := range r.filters {
-			filter.ExecuteBefore(hContext)
-		}
+	if !r.executePreFilters(hContext, r.filters) {
+		return
 	}
 	// prepare the request info
 	callingRequest := hContext.Request
 	method := strings.ToUpper(callingRequest.Method)
 	cleanPath := CleanPath(callingRequest.URL.Path)
+	if r.pathPolicy != PathPolicyLenient {
+		cleanPath = normalizePath(callingRequest.URL.Path)
+	}
 	hContext.Path = cleanPath
 	// check if there is a global handler. if so use that and be done.
 	globalHandler := r.globalHandlers[method]
@@ -247,8 +413,17 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 	// check to see if there is a matching route
-	match := r.routeMatcher.MatchPathToRoute(method, cleanPath, callingRequest)
-	if match == nil || len(match.Node.routes) == 0 {
+	matchedNode := r.matchRoute(hContext, method, cleanPath)
+	if matchedNode != nil && nodeHasRoutes(matchedNode, "") && r.pathPolicy != PathPolicyLenient {
+		if canonical := canonicalPath(matchedNode, method, cleanPath); canonical != callingRequest.URL.Path {
+			if r.pathPolicy == PathPolicyRedirect {
+				r.redirectToPath(hContext, canonical)
+				return
+			}
+			matchedNode = nil
+		}
+	}
+	if matchedNode == nil || !nodeHasRoutes(matchedNode, "") {
 		// check to see if there is a file match
 		fileExists, filename := r.shouldServeStaticFile(respWriter, req, cleanPath)
 		if fileExists {
@@ -260,13 +435,32 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		r.emitError(hContext, http.StatusNotFound, "Not Found", RouterGenericErrorCode, nil)
 		return
 	}
-	route := match.Node.RouteForMethod(method)
+	route, conditionStatus := matchedNode.RouteForRequest(method, req)
+	if route == nil && conditionStatus == 0 && method == http.MethodHead && r.autoHead {
+		// respond using the GET route but don't write the body
+		route, conditionStatus = matchedNode.RouteForRequest(http.MethodGet, req)
+		respWriter.discardBody = route != nil
+	}
+	if route == nil && conditionStatus != 0 {
+		// routes exist for the method but the request doesn't meet their conditions
+		r.emitError(hContext, conditionStatus, http.StatusText(conditionStatus), RouterGenericErrorCode, nil)
+		return
+	}
+	if route == nil && method == http.MethodOptions && r.autoOptions {
+		respWriter.Header().Set("Allow", strings.Join(r.allowedMethods(matchedNode), ", "))
+		respWriter.WriteHeader(http.StatusOK)
+		r.executePostFilters(hContext)
+		return
+	}
 	if route == nil {
 		// method not allowed
+		if r.allowHeaderOnError {
+			respWriter.Header().Set("Allow", strings.Join(r.allowedMethods(matchedNode), ", "))
+		}
 		r.emitError(hContext, http.StatusMethodNotAllowed, "Method Not Allowed", RouterGenericErrorCode, nil)
 		return
 	}
-	if match.Node.nodeType == ComponentTypeCatchAll {
+	if matchedNode.nodeType == ComponentTypeCatchAll {
 		// check to see if we should serve a static file at that location before falling
 		// through to the catch all
 		fileExists, filename := r.shouldServeStaticFile(respWriter, req, cleanPath)
@@ -276,19 +470,103 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 			return
 		}
 	}
+	// execute the route specific filters
+	hContext.route = route
+	if !r.executePreFilters(hContext, route.Filters()) {
+		return
+	}
 	handler := route.Handler
 	if handler == nil {
 		r.emitError(hContext, http.StatusInternalServerError, "No Handler defined", RouterGenericErrorCode, nil)
 		return
 	}
-	hContext.Parameters = NewParametersWithMap(match.Params)
-	if match.CatchAllValue != "" {
-		hContext.CatchAllValue = match.CatchAllValue
+	if route.group != nil {
+		if chainHandlers := route.group.ChainHandlers(); len(chainHandlers) > 0 {
+			// execute the group chain handlers before the route handler
+			handler = r.NewChain(chainHandlers...).Then(handler.Serve)
+		}
 	}
 	handler.Serve(hContext)
 	r.executePostFilters(hContext)
 }
 
+// allowedMethods - returns the sorted list of methods that can be requested for the
+// node, including any methods that the router will handle automatically
+func (r *Router) allowedMethods(node *Node) []string {
+	methods := make([]string, 0, len(node.routes)+2)
+	for method := range node.routes {
+		if method != MethodAny && node.routeForMethodKey(method) != nil {
+			methods = append(methods, method)
+		}
+	}
+	if r.autoHead && node.RouteForMethod(http.MethodGet) != nil && node.RouteForMethod(http.MethodHead) == nil {
+		methods = append(methods, http.MethodHead)
+	}
+	if r.autoOptions && node.RouteForMethod(http.MethodOptions) == nil {
+		methods = append(methods, http.MethodOptions)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// variablesForRoute - returns the variables used to resolve the route path. route
+// variables take precedence over the Router variables
+func (r *Router) variablesForRoute(route *Route) map[string]string {
+	if len(route.variables) == 0 {
+		return r.variables
+	}
+	variables := make(map[string]string, len(r.variables)+len(route.variables))
+	for variable, value := range r.variables {
+		variables[variable] = value
+	}
+	for variable, value := range route.variables {
+		variables[variable] = value
+	}
+	return variables
+}
+
+// matchRoute - finds the node matching the method and path (using the route cache
+// if enabled) and stores the matched parameters in the context
+func (r *Router) matchRoute(ctx *HandlerContext, method string, cleanPath string) *Node {
+	// the generation must be read before matching so that a match against routes
+	// that are replaced during matching is not cached
+	cacheGeneration := r.cache.Generation()
+	if r.ShouldCacheMatchedRoutes {
+		if entry := r.cache.Get(method, cleanPath); entry.HasValue() {
+			ctx.Parameters = entry.Params
+			ctx.CatchAllValue = entry.CatchAllValue
+			return entry.Node
+		}
+	}
+	match := r.routeMatcher.MatchPathToRoute(method, cleanPath, ctx.Request)
+	if match == nil {
+		return nil
+	}
+	matchedNode := match.Node
+	ctx.Parameters = match.Parameters()
+	ctx.CatchAllValue = match.CatchAllValue
+	r.routeMatcher.ReleaseMatch(match)
+	if r.ShouldCacheMatchedRoutes {
+		r.cache.PutForGeneration(cacheGeneration, method, cleanPath, CacheEntry{
+			Node:          matchedNode,
+			Params:        ctx.Parameters,
+			CatchAllValue: ctx.CatchAllValue,
+		})
+	}
+	return matchedNode
+}
+
+// CacheStats - returns the route cache hit, miss and eviction counters
+func (r *Router) CacheStats() CacheStats {
+	return r.cache.Stats()
+}
+
+// SetRouteCacheSize - sets the maximum number of matched routes that will be cached
+func (r *Router) SetRouteCacheSize(size int) {
+	r.cache.MaxEntries = size
+	r.cache.Clear()
+}
+
 func (r *Router) shouldServeStaticFile(w http.ResponseWriter, req *http.Request, servePath string) (fileExists bool, filePath string) {
 	if r.staticLocations != nil && len(r.staticLocations) > 0 {
 		for _, staticDir := range r.staticLocations {
@@ -325,38 +603,114 @@ func (r *Router) shouldServeStaticFile(w http.ResponseWriter, req *http.Request,
 
 // error handling
 func (r *Router) emitError(context *HandlerContext, statusCode int, errMessage string, errCode RouterErrorCode, originalErr error) {
-	routingError := RoutingError{
+	r.emitRoutingError(context, RoutingError{
 		StatusCode: statusCode,
 		Message:    errMessage,
 		ErrorCode:  errCode,
 		Error:      originalErr,
-	}
+	})
+}
+
+// emitRoutingError - records the error and calls the error handler for the status
+// code (falling back to the generic error handler, then a plain response). field
+// errors (see Bind) are written as JSON if there is no error handler
+func (r *Router) emitRoutingError(context *HandlerContext, routingError RoutingError) {
+	statusCode := routingError.StatusCode
 	context.Errors = append(context.Errors, routingError)
-	// try to call specific error handler
-	errHandler := r.errorHandlers[statusCode]
+	// try to call specific error handler (preferring handlers for the matched route)
+	errHandler := r.errorHandlerForStatus(statusCode)
+	if context.route != nil && context.route.errorHandlers[statusCode] != nil {
+		errHandler = context.route.errorHandlers[statusCode]
+	}
 	if errHandler != nil {
 		errHandler.Serve(context)
 		r.executePostFilters(context)
 		return
 	}
 	// if generic error handler defined, call that
-	if r.errorHandler != nil {
-		r.errorHandler.Serve(context)
+	if genericHandler := r.genericErrorHandler(); genericHandler != nil {
+		genericHandler.Serve(context)
 		r.executePostFilters(context)
 		return
 	}
 	// return a generic http error
-	errorHandler(context.ResponseWriter, context.Request,
-		errMessage, statusCode)
+	if fieldErrs, ok := routingError.Error.(FieldErrors); ok {
+		writeFieldErrors(context.ResponseWriter, statusCode, routingError.Message, fieldErrs)
+	} else {
+		errorHandler(context.ResponseWriter, context.Request, routingError.Message, statusCode)
+	}
 	r.executePostFilters(context)
 }
 
+// errorHandlerForStatus - returns the error handler for the status code, checking
+// the parent routers if this router has been mounted
+func (r *Router) errorHandlerForStatus(statusCode int) ContextHandler {
+	for router := r; router != nil; router = router.parent {
+		if errHandler := router.errorHandlers[statusCode]; errHandler != nil {
+			return errHandler
+		}
+	}
+	return nil
+}
+
+// genericErrorHandler - returns the router error handler, checking the parent
+// routers if this router has been mounted
+func (r *Router) genericErrorHandler() ContextHandler {
+	for router := r; router != nil; router = router.parent {
+		if router.errorHandler != nil {
+			return router.errorHandler
+		}
+	}
+	return nil
+}
+
+// executePreFilters - executes the filters in order. returns false if a filter has
+// halted the request (in which case the halt has been handled)
+func (r *Router) executePreFilters(ctx *HandlerContext, filters []HaltingFilter) bool {
+	for _, filter := range filters {
+		result := filter.Before(ctx)
+		if !result.Halt && ctx.abortResult != nil {
+			result = *ctx.abortResult
+		}
+		if result.Halt {
+			r.haltRequest(ctx, result)
+			return false
+		}
+	}
+	return true
+}
+
+// haltRequest - records why the request was halted and finishes the request
+func (r *Router) haltRequest(ctx *HandlerContext, result FilterResult) {
+	reason := result.Reason
+	if reason == "" {
+		reason = "Request halted by filter"
+	}
+	checkedWriter, isChecked := ctx.ResponseWriter.(*CheckedResponseWriter)
+	hasWritten := isChecked && checkedWriter.HeaderWritten()
+	if !hasWritten && result.StatusCode != 0 {
+		r.emitError(ctx, result.StatusCode, reason, FilterHaltedErrorCode, nil)
+		return
+	}
+	ctx.Errors = append(ctx.Errors, RoutingError{
+		StatusCode: result.StatusCode,
+		Message:    reason,
+		ErrorCode:  FilterHaltedErrorCode,
+	})
+	r.executePostFilters(ctx)
+}
+
 func (r *Router) executePostFilters(ctx *HandlerContext) {
 	hasDonePost := ctx.internalState[StateKeyHasExecutedPostFilters]
 	if hasDonePost == nil {
+		if ctx.route != nil {
+			for _, filter := range ctx.route.Filters() {
+				filter.After(ctx)
+			}
+		}
 		if r.filters != nil && len(r.filters) > 0 {
 			for _, filter := range r.filters {
-				filter.ExecuteAfter(ctx)
+				filter.After(ctx)
 			}
 		}
 		ctx.internalState[StateKeyHasExecutedPostFilters] = true
@@ -399,42 +753,49 @@ func (r *Router) recoverPanic(handlerContext *HandlerContext) {
 
 // PrintTreeInfo prints debugging information about all registered Routes
 func (r *Router) PrintTreeInfo() {
-	for _, node := range r.routes.nodes {
+	for _, node := range r.Tree().nodes {
 		fmt.Println(" - ", node)
 		printSubNodes(node, 0)
 	}
 }
 
-// PrintRoutes prints route registration information
+// PrintRoutes prints route registration information, including the filters that
+// will be executed for each route. Routes for mounted Routers and API versions are
+// listed with their full paths
 func (r *Router) PrintRoutes() {
-	fmt.Println("")
-	nodes := r.routes.nodes
-	for _, node := range nodes {
-		for _, route := range node.routes {
-			printRouteDebugInfo(route)
+	routes := r.Routes()
+	// the method column is wide enough for the longest (e.g.: custom) method
+	width := 9
+	for _, info := range routes {
+		if len(info.Method) > width {
+			width = len(info.Method)
 		}
-		printSubRoutes(node)
+	}
+	fmt.Println("")
+	for _, info := range routes {
+		printRouteDebugInfo(info, width)
 	}
 	fmt.Println("")
 }
 
-func printSubRoutes(node *Node) {
-	if node.HasChildren() {
-		for _, node := range node.nodes {
-			for _, route := range node.routes {
-				printRouteDebugInfo(route)
-			}
-			printSubRoutes(node)
-		}
+func printRouteDebugInfo(info RouteInfo, width int) {
+	desc := info.Description
+	if info.label != "" {
+		desc = fmt.Sprintf("[%s] %s", info.label, desc)
+	}
+	fmt.Printf("%*s   %-50s %s\n", width, info.Method, info.Pattern, desc)
+	if len(info.Filters) > 0 {
+		fmt.Printf("%*s   filters: %s\n", width, "", strings.Join(info.FilterNames(), ", "))
 	}
 }
 
-func printRouteDebugInfo(route *Route) {
-	desc := route.Info[RouteInfoKeyDescription]
-	if desc == nil {
-		desc = ""
+// filterNames - returns the type names of the filters
+func filterNames(filters []HaltingFilter) []string {
+	names := make([]string, len(filters))
+	for idx, filter := range filters {
+		names[idx] = filterName(filter)
 	}
-	fmt.Printf("%9s   %-50s %s\n", route.Method, route.PathFormat, desc)
+	return names
 }
 
 func printSubNodes(node *Node, level int) {
@@ -11,6 +11,8 @@ package goro_test
 
 import (
 	"github.com/theyakka/goro"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -38,6 +40,436 @@ func TestVariableSubMissRoute(t *testing.T) {
 	expectNotHitResult(t, router, "GET", "/colors/red")
 }
 
+func TestRegexConstrainedRoute(t *testing.T) {
+	expectHitResult(t, router, "GET", "/products/1234")
+	expectNotHitResult(t, router, "GET", "/products/abc")
+	expectHitResult(t, router, "GET", "/files/my-file")
+	expectNotHitResult(t, router, "GET", "/files/MyFile1")
+}
+
+func TestRegexConstrainedRouteSelection(t *testing.T) {
+	hitName := ""
+	constrainedRouter := goro.NewRouter()
+	constrainedRouter.GET("/users/:id(^[0-9]+$)").HandleFunc(func(ctx *goro.HandlerContext) {
+		hitName = "id=" + ctx.Parameters.GetFirstString("id")
+	})
+	constrainedRouter.GET("/users/me").HandleFunc(func(ctx *goro.HandlerContext) {
+		hitName = "me"
+	})
+	execMockRequest(constrainedRouter, "GET", "/users/42")
+	if hitName != "id=42" {
+		t.Error("Expected the numeric route to be hit but got", hitName)
+	}
+	execMockRequest(constrainedRouter, "GET", "/users/me")
+	if hitName != "me" {
+		t.Error("Expected the 'me' route to be hit but got", hitName)
+	}
+}
+
+func TestMatchPrecedence(t *testing.T) {
+	hitName := ""
+	precedenceRouter := goro.NewRouter()
+	namedHandler := func(name string) goro.ContextHandlerFunc {
+		return func(ctx *goro.HandlerContext) {
+			hitName = name
+		}
+	}
+	precedenceRouter.GET("/docs/*").HandleFunc(namedHandler("docs-catchall"))
+	precedenceRouter.GET("/docs/:section/*").HandleFunc(namedHandler("section-catchall"))
+	precedenceRouter.GET("/:area/intro/start").HandleFunc(namedHandler("area-start"))
+	precedenceRouter.GET("/docs/:section").HandleFunc(namedHandler("section"))
+	precedenceRouter.GET("/docs/latest").HandleFunc(namedHandler("latest"))
+	expectations := map[string]string{
+		"/docs/latest":           "latest",
+		"/docs/guide":            "section",
+		"/docs/guide/setup":      "section-catchall",
+		"/docs/intro/start":      "area-start",
+		"/docs/guide/setup/more": "section-catchall",
+	}
+	for path, expected := range expectations {
+		hitName = ""
+		execMockRequest(precedenceRouter, "GET", path)
+		if hitName != expected {
+			t.Error("Expected", path, "to hit", expected, "but got", hitName)
+		}
+	}
+}
+
+// precedenceRoutes - routes used to test the matching precedence table. they are
+// registered in several orders to check that the order does not affect matching
+var precedenceRoutes = []string{
+	"/items/new",
+	"/items/:id<int>(^[1-9][0-9]*$)",
+	"/items/:id<int>",
+	"/items/:slug([a-z-]+)",
+	"/items/:name",
+	"/items/*",
+	"/items/:name/edit",
+	"/items/:name/*",
+	"/*",
+}
+
+func TestPrecedenceTable(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/items/new", "/items/new"},
+		{"/items/42", "/items/:id<int>(^[1-9][0-9]*$)"},
+		{"/items/042", "/items/:id<int>"},
+		{"/items/-1", "/items/:id<int>"},
+		{"/items/blue-widget", "/items/:slug([a-z-]+)"},
+		{"/items/Widget", "/items/:name"},
+		{"/items/new/edit", "/items/:name/edit"},
+		{"/items/new/history", "/items/:name/*"},
+		{"/items/a/b/c", "/items/:name/*"},
+		{"/other/path", "/*"},
+	}
+	orders := map[string][]string{
+		"forward": precedenceRoutes,
+		"reverse": reversedStrings(precedenceRoutes),
+	}
+	for orderName, routes := range orders {
+		hitName := ""
+		precedenceRouter := goro.NewRouter()
+		for _, route := range routes {
+			routePath := route
+			precedenceRouter.GET(routePath).HandleFunc(func(ctx *goro.HandlerContext) {
+				hitName = routePath
+			})
+		}
+		for _, test := range tests {
+			hitName = ""
+			execMockRequest(precedenceRouter, "GET", test.path)
+			if hitName != test.expected {
+				t.Error("Expected", test.path, "to hit", test.expected, "but got", hitName,
+					"(registered in", orderName, "order)")
+			}
+		}
+	}
+}
+
+func TestRoutePriority(t *testing.T) {
+	tests := []struct {
+		priorities map[string]int
+		path       string
+		expected   string
+	}{
+		{nil, "/pages/about", "/pages/about"},
+		{map[string]int{"/pages/:slug": 1}, "/pages/about", "/pages/:slug"},
+		{map[string]int{"/pages/*": 1}, "/pages/about", "/pages/*"},
+		{map[string]int{"/pages/*": 1}, "/pages/about/team", "/pages/*"},
+		{map[string]int{"/pages/about": -1}, "/pages/about", "/pages/:slug"},
+		{map[string]int{"/pages/:slug": 2, "/pages/*": 1}, "/pages/about", "/pages/:slug"},
+	}
+	for _, test := range tests {
+		hitName := ""
+		priorityRouter := goro.NewRouter()
+		for _, routePath := range []string{"/pages/about", "/pages/:slug", "/pages/*"} {
+			routePath := routePath
+			route := priorityRouter.GET(routePath).HandleFunc(func(ctx *goro.HandlerContext) {
+				hitName = routePath
+			})
+			if priority, ok := test.priorities[routePath]; ok {
+				route.Priority(priority)
+			}
+		}
+		execMockRequest(priorityRouter, "GET", test.path)
+		if hitName != test.expected {
+			t.Error("Expected", test.path, "with priorities", test.priorities, "to hit", test.expected,
+				"but got", hitName)
+		}
+	}
+}
+
+func reversedStrings(values []string) []string {
+	reversed := make([]string, len(values))
+	for idx, value := range values {
+		reversed[len(values)-1-idx] = value
+	}
+	return reversed
+}
+
+func TestAutomaticHeadAndOptions(t *testing.T) {
+	methodRouter := goro.NewRouter()
+	methodRouter.GET("/items").HandleFunc(func(ctx *goro.HandlerContext) {
+		wasHit = true
+		ctx.ResponseWriter.Write([]byte("items"))
+	})
+	methodRouter.POST("/items").HandleFunc(testHandler)
+
+	// HEAD uses the GET route without a body
+	req, _ := http.NewRequest("HEAD", "/items", nil)
+	w := httptest.NewRecorder()
+	methodRouter.ServeHTTP(w, req)
+	if !wasHit || w.Code != http.StatusOK || w.Body.Len() != 0 {
+		t.Error("Expected HEAD to hit the GET route with no body. code =", w.Code, "body =", w.Body.String())
+	}
+	resetState()
+
+	// OPTIONS lists the allowed methods
+	req, _ = http.NewRequest("OPTIONS", "/items", nil)
+	w = httptest.NewRecorder()
+	methodRouter.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("Allow") != "GET, HEAD, OPTIONS, POST" {
+		t.Error("Expected OPTIONS to list the allowed methods but got", w.Code, w.Header().Get("Allow"))
+	}
+
+	// 405 includes the Allow header
+	req, _ = http.NewRequest("DELETE", "/items", nil)
+	w = httptest.NewRecorder()
+	methodRouter.ServeHTTP(w, req)
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "GET, HEAD, OPTIONS, POST" {
+		t.Error("Expected 405 with an Allow header but got", w.Code, w.Header().Get("Allow"))
+	}
+
+	// HEAD and OPTIONS routes registered for other paths don't hide the GET routes
+	// when only the routes registered for the method are considered
+	mixedRouter := goro.NewRouter()
+	mixedRouter.SetMethodNotAllowedIsError(false)
+	mixedRouter.GET("/a").HandleFunc(testHandler)
+	mixedRouter.HEAD("/b").HandleFunc(testHandler)
+	mixedRouter.OPTIONS("/c").HandleFunc(testHandler)
+	expectHitResult(t, mixedRouter, "HEAD", "/a")
+	req, _ = http.NewRequest("OPTIONS", "/a", nil)
+	w = httptest.NewRecorder()
+	mixedRouter.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("Allow") != "GET, HEAD, OPTIONS" {
+		t.Error("Expected OPTIONS to list the allowed methods but got", w.Code, w.Header().Get("Allow"))
+	}
+	resetState()
+
+	// disabled behaviours fall back to 405 without an Allow header
+	methodRouter.SetAutoHead(false)
+	methodRouter.SetAutoOptions(false)
+	methodRouter.SetAllowHeaderOnMethodNotAllowed(false)
+	for _, method := range []string{"HEAD", "OPTIONS"} {
+		req, _ = http.NewRequest(method, "/items", nil)
+		w = httptest.NewRecorder()
+		methodRouter.ServeHTTP(w, req)
+		if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "" {
+			t.Error("Expected", method, "to be 405 with no Allow header but got", w.Code, w.Header().Get("Allow"))
+		}
+	}
+	resetState()
+}
+
+func TestCaseSensitiveMatching(t *testing.T) {
+	var params *goro.Parameters
+	caseRouter := goro.NewRouter()
+	caseRouter.GET("/Files/:fileID").HandleFunc(func(ctx *goro.HandlerContext) {
+		wasHit = true
+		params = ctx.Parameters
+	})
+
+	expectHitResult(t, caseRouter, "GET", "/files/ABC")
+	caseRouter.SetCaseSensitive(true)
+	expectNotHitResult(t, caseRouter, "GET", "/files/ABC")
+	expectHitResult(t, caseRouter, "GET", "/Files/ABC")
+	if keys := params.Keys(); len(keys) != 1 || keys[0] != "fileID" {
+		t.Error("Expected the parameter name to keep its declared case but got", keys)
+	}
+	if params.GetFirstString("fileID") != "ABC" || params.GetFirstString("fileid") != "ABC" {
+		t.Error("Expected fileID to be ABC but got", params.GetFirstString("fileID"))
+	}
+}
+
+func TestOptionalSegments(t *testing.T) {
+	var params *goro.Parameters
+	optionalRouter := goro.NewRouter()
+	optionalRouter.GET("/docs/:lang?/:page").HandleFunc(func(ctx *goro.HandlerContext) {
+		wasHit = true
+		params = ctx.Parameters
+	}).Name("docs")
+	if errs := optionalRouter.Validate(); len(errs) != 0 {
+		t.Error("Expected the optional route variants not to be reported but got", errs)
+	}
+	// a wildcard registered by a different route is still ambiguous
+	optionalRouter.GET("/docs/:section").HandleFunc(testHandler)
+	if errs := optionalRouter.Validate(); len(errs) != 1 || errs[0].Code != goro.RegistrationErrorAmbiguousWildcard {
+		t.Error("Expected an ambiguous wildcard error but got", errs)
+	}
+	optionalRouter.Remove("GET", "/docs/:section")
+	strictRouter := goro.NewRouter()
+	strictRouter.SetStrictRegistration(true)
+	strictRouter.GET("/docs/:lang?/:page")
+
+	expectHitResult(t, optionalRouter, "GET", "/docs/en/intro")
+	if params.GetFirstString("lang") != "en" || params.GetFirstString("page") != "intro" {
+		t.Error("Expected lang=en and page=intro but got", params.Keys())
+	}
+	expectHitResult(t, optionalRouter, "GET", "/docs/intro")
+	if keys := params.Keys(); len(keys) != 1 || params.GetFirstString("page") != "intro" {
+		t.Error("Expected only page=intro but got", keys)
+	}
+	expectNotHitResult(t, optionalRouter, "GET", "/docs")
+	if url, urlErr := optionalRouter.URL("docs", goro.P{"page": "intro"}); urlErr != nil || url != "/docs/intro" {
+		t.Error("Expected '/docs/intro' but got", url, urlErr)
+	}
+	if !optionalRouter.Remove("GET", "/docs/:lang?/:page") {
+		t.Error("Expected the optional route to be removed")
+	}
+	expectNotHitResult(t, optionalRouter, "GET", "/docs/intro")
+	expectNotHitResult(t, optionalRouter, "GET", "/docs/en/intro")
+}
+
+func TestPatternSegments(t *testing.T) {
+	hitName := ""
+	var params *goro.Parameters
+	patternRouter := goro.NewRouter()
+	patternRouter.GET("/files/:name.:ext").HandleFunc(func(ctx *goro.HandlerContext) {
+		hitName, params = "file", ctx.Parameters
+	}).Name("file")
+	patternRouter.GET("/files/:name").HandleFunc(func(ctx *goro.HandlerContext) {
+		hitName, params = "name", ctx.Parameters
+	})
+	patternRouter.GET("/api/:from-:to/changes").HandleFunc(func(ctx *goro.HandlerContext) {
+		hitName, params = "range", ctx.Parameters
+	})
+	// fixed parts containing a colon are not patterns
+	patternRouter.GET("/books/urn:isbn").HandleFunc(func(ctx *goro.HandlerContext) {
+		hitName, params = "urn", ctx.Parameters
+	})
+
+	execMockRequest(patternRouter, "GET", "/files/archive.tar.gz")
+	if hitName != "file" || params.GetFirstString("name") != "archive.tar" || params.GetFirstString("ext") != "gz" {
+		t.Error("Expected name=archive.tar and ext=gz but got", hitName, params.Keys())
+	}
+	execMockRequest(patternRouter, "GET", "/files/README")
+	if hitName != "name" || params.GetFirstString("name") != "README" {
+		t.Error("Expected the plain wildcard for 'README' but got", hitName)
+	}
+	execMockRequest(patternRouter, "GET", "/files/.profile")
+	if hitName != "name" {
+		t.Error("Expected the plain wildcard for '.profile' but got", hitName)
+	}
+	execMockRequest(patternRouter, "GET", "/api/2-5/changes")
+	if hitName != "range" || params.GetFirstString("from") != "2" || params.GetFirstString("to") != "5" {
+		t.Error("Expected from=2 and to=5 but got", hitName, params.Keys())
+	}
+	execMockRequest(patternRouter, "GET", "/books/urn:isbn")
+	if hitName != "urn" || len(params.Keys()) != 0 {
+		t.Error("Expected the fixed 'urn:isbn' route but got", hitName, params.Keys())
+	}
+	if status := statusFor(patternRouter, "GET", "/books/urn:1234"); status != http.StatusNotFound {
+		t.Error("Expected 404 for 'urn:1234' but got", status)
+	}
+	if url, urlErr := patternRouter.URL("file", goro.P{"name": "notes", "ext": "txt"}); urlErr != nil || url != "/files/notes.txt" {
+		t.Error("Expected '/files/notes.txt' but got", url, urlErr)
+	}
+}
+
+func TestCatchAllSegments(t *testing.T) {
+	var hitCtx *goro.HandlerContext
+	catchAllRouter := goro.NewRouter()
+	catchAllRouter.GET("/repos/:owner/:repo/+path").HandleFunc(func(ctx *goro.HandlerContext) {
+		wasHit = true
+		hitCtx = ctx
+	})
+	catchAllRouter.GET("/static/*").HandleFunc(func(ctx *goro.HandlerContext) {
+		wasHit = true
+		hitCtx = ctx
+	})
+
+	expectHitResult(t, catchAllRouter, "GET", "/repos/yakka/goro/docs/guide/intro.md")
+	if value := hitCtx.Parameters.GetFirstString("path"); value != "docs/guide/intro.md" {
+		t.Error("Expected path=docs/guide/intro.md but got", value)
+	}
+	segments := hitCtx.Parameters.GetSegments("path")
+	if len(segments) != 3 || segments[0] != "docs" || segments[2] != "intro.md" {
+		t.Error("Expected 3 path segments but got", segments)
+	}
+	if hitCtx.Parameters.GetFirstString("owner") != "yakka" || len(hitCtx.CatchAllSegments()) != 3 {
+		t.Error("Expected owner=yakka and 3 catch-all segments but got", hitCtx.Parameters.Keys())
+	}
+	// '+' requires at least one segment, '*' matches zero or more
+	expectNotHitResult(t, catchAllRouter, "GET", "/repos/yakka/goro")
+	expectHitResult(t, catchAllRouter, "GET", "/static")
+	if hitCtx.CatchAllValue != "" || len(hitCtx.CatchAllSegments()) != 0 {
+		t.Error("Expected an empty catch-all value but got", hitCtx.CatchAllValue)
+	}
+	expectHitResult(t, catchAllRouter, "GET", "/static/css/site.css")
+	if hitCtx.CatchAllValue != "css/site.css" {
+		t.Error("Expected css/site.css but got", hitCtx.CatchAllValue)
+	}
+
+	// a root '+' catch-all does not match the root path
+	rootRouter := goro.NewRouter()
+	rootRouter.GET("/+path").HandleFunc(testHandler)
+	if status := statusFor(rootRouter, "GET", "/"); status != http.StatusNotFound {
+		t.Error("Expected 404 for '/' but got", status)
+	}
+	expectHitResult(t, rootRouter, "GET", "/about")
+}
+
+func TestRouterMethods(t *testing.T) {
+	hitMethod := ""
+	methodsRouter := goro.NewRouter()
+	methodHandler := func(ctx *goro.HandlerContext) {
+		hitMethod = ctx.Request.Method
+	}
+	methodsRouter.DELETE("/items/:id").HandleFunc(methodHandler)
+	methodsRouter.PATCH("/items/:id").HandleFunc(methodHandler)
+	methodsRouter.HEAD("/items/:id").HandleFunc(methodHandler)
+	methodsRouter.OPTIONS("/items/:id").HandleFunc(methodHandler)
+	methodsRouter.Add("PROPFIND", "/items/:id").HandleFunc(methodHandler)
+	for _, method := range []string{"DELETE", "PATCH", "HEAD", "OPTIONS", "PROPFIND"} {
+		hitMethod = ""
+		execMockRequest(methodsRouter, method, "/items/1")
+		if hitMethod != method {
+			t.Error("Expected the", method, "route to be hit but got", hitMethod)
+		}
+	}
+	if status := statusFor(methodsRouter, "GET", "/items/1"); status != http.StatusMethodNotAllowed {
+		t.Error("Expected 405 for GET but got", status)
+	}
+}
+
+func TestAnyAndMatchRoutes(t *testing.T) {
+	hitName := ""
+	methodsRouter := goro.NewRouter()
+	shared := methodsRouter.Match([]string{"get", "POST", "PURGE"}, "/cache").HandleFunc(func(ctx *goro.HandlerContext) {
+		hitName = "match-" + ctx.Request.Method
+	})
+	methodsRouter.Any("/anything").HandleFunc(func(ctx *goro.HandlerContext) {
+		hitName = "any-" + ctx.Request.Method
+	})
+	methodsRouter.GET("/anything").HandleFunc(func(ctx *goro.HandlerContext) {
+		hitName = "get"
+	})
+	if methods := shared.Methods(); len(methods) != 3 || methods[0] != "GET" || methods[2] != "PURGE" {
+		t.Error("Expected the route to be registered for GET, POST and PURGE but got", methods)
+	}
+	shared.Describe("shared")
+	for _, method := range []string{"GET", "POST", "PURGE"} {
+		execMockRequest(methodsRouter, method, "/cache")
+		if hitName != "match-"+method {
+			t.Error("Expected the shared route for", method, "but got", hitName)
+		}
+	}
+	if status := statusFor(methodsRouter, "PUT", "/cache"); status != http.StatusMethodNotAllowed {
+		t.Error("Expected 405 for PUT but got", status)
+	}
+	execMockRequest(methodsRouter, "DELETE", "/anything")
+	if hitName != "any-DELETE" {
+		t.Error("Expected the any route for DELETE but got", hitName)
+	}
+	execMockRequest(methodsRouter, "GET", "/anything")
+	if hitName != "get" {
+		t.Error("Expected the GET route to take precedence but got", hitName)
+	}
+	if !methodsRouter.Remove("POST", "/cache") {
+		t.Error("Expected the POST registration to be removed")
+	}
+	if status := statusFor(methodsRouter, "POST", "/cache"); status != http.StatusMethodNotAllowed {
+		t.Error("Expected 405 for POST after removal but got", status)
+	}
+	execMockRequest(methodsRouter, "PURGE", "/cache")
+	if hitName != "match-PURGE" {
+		t.Error("Expected the shared route to remain for PURGE but got", hitName)
+	}
+}
+
 func testHandler(_ *goro.HandlerContext) {
 	wasHit = true
 }
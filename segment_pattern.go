@@ -0,0 +1,128 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro
+
+import (
+	"fmt"
+	"strings"
+)
+
+// segmentPattern - a path part that combines wildcards with fixed text (e.g.:
+// ':name.:ext' or ':from-:to'). The fixed text between the wildcards separates
+// the values
+type segmentPattern struct {
+	// names - the parameter names in the order they appear
+	names []string
+
+	// literals - the fixed text before, between and after the parameters. there is
+	// always one more literal than there are names and only the first and last
+	// literals can be empty
+	literals []string
+}
+
+// isPatternPart - is the string (part) a wildcard part containing fixed text. Pattern
+// parts start with a wildcard and every following wildcard is preceded by a delimiter
+// (a character that cannot be used in a name, e.g.: '.' or '-') so that fixed parts
+// containing a colon (e.g.: 'urn:isbn') are matched as they are. Parts with a type
+// or constraint (e.g.: ':id<int>') are never pattern parts
+func isPatternPart(part string) bool {
+	if !isWildcardPart(part) || strings.Count(part, ":") < 2 || strings.ContainsAny(part, "<(") {
+		return false
+	}
+	for idx := 1; idx < len(part); idx++ {
+		if part[idx] == ':' && isParamNameByte(part[idx-1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseSegmentPattern - splits a pattern part into the parameter names and the
+// fixed text around them. Parameter names consist of letters, digits and '_'
+func parseSegmentPattern(part string) (*segmentPattern, error) {
+	pattern := &segmentPattern{}
+	literal := ""
+	for idx := 0; idx < len(part); {
+		if part[idx] != ':' {
+			literal += string(part[idx])
+			idx++
+			continue
+		}
+		nameEnd := idx + 1
+		for nameEnd < len(part) && isParamNameByte(part[nameEnd]) {
+			nameEnd++
+		}
+		if nameEnd == idx+1 {
+			return nil, fmt.Errorf("Missing wildcard name. part='%s'", part)
+		}
+		if len(pattern.names) > 0 && literal == "" {
+			return nil, fmt.Errorf("Wildcards must be separated by fixed text. part='%s'", part)
+		}
+		pattern.literals = append(pattern.literals, literal)
+		pattern.names = append(pattern.names, part[idx+1:nameEnd])
+		literal = ""
+		idx = nameEnd
+	}
+	pattern.literals = append(pattern.literals, literal)
+	return pattern, nil
+}
+
+// isParamNameByte - returns true if the byte can be used in a pattern parameter name
+func isParamNameByte(b byte) bool {
+	return b == '_' || (b >= '0' && b <= '9') || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// shape - the fixed text of the pattern. patterns with the same shape accept the
+// same values
+func (sp *segmentPattern) shape() string {
+	return strings.Join(sp.literals, ":")
+}
+
+// appendPatternParams - splits the segment using the node's pattern and appends the
+// values to the matched parameters. Separators are matched from the end of the
+// segment so that earlier values take the longest match (e.g.: ':name.:ext' splits
+// 'archive.tar.gz' into 'archive.tar' and 'gz'). Returns false (leaving the
+// parameters unchanged) if the segment doesn't match or a value would be empty
+func (match *Match) appendPatternParams(node *Node, segment string) bool {
+	pattern := node.pattern
+	prefix := pattern.literals[0]
+	suffix := pattern.literals[len(pattern.literals)-1]
+	if len(segment) <= len(prefix)+len(suffix) || !strings.HasPrefix(segment, prefix) ||
+		!strings.HasSuffix(segment, suffix) {
+		return false
+	}
+	remainder := segment[len(prefix) : len(segment)-len(suffix)]
+	paramCount := len(match.params)
+	for nameIdx := len(pattern.names) - 1; nameIdx > 0; nameIdx-- {
+		separator := pattern.literals[nameIdx]
+		// the value after the separator must not be empty
+		separatorIdx := strings.LastIndex(remainder[:len(remainder)-1], separator)
+		if separatorIdx <= 0 {
+			match.params = match.params[:paramCount]
+			return false
+		}
+		match.params = append(match.params, matchParam{
+			node:  node,
+			key:   pattern.names[nameIdx],
+			value: remainder[separatorIdx+len(separator):],
+		})
+		remainder = remainder[:separatorIdx]
+	}
+	match.params = append(match.params, matchParam{
+		node:  node,
+		key:   pattern.names[0],
+		value: remainder,
+	})
+	// the values were appended from last to first
+	for left, right := paramCount, len(match.params)-1; left < right; left, right = left+1, right-1 {
+		match.params[left], match.params[right] = match.params[right], match.params[left]
+	}
+	return true
+}
@@ -33,15 +33,18 @@ func TestMain(m *testing.M) {
 	router.SetStringVariable("color", "blue")
 	// router tests
 	router.GET("/").HandleFunc(testHandler)
-	router.GET("/users/:id").HandleFunc(testParamsHandler)
-	router.GET("/users/:id/action/:action").HandleFunc(testParamsHandler)
-	router.GET("/colors/$color").HandleFunc(testHandler)
+	router.GET("/users/:id").HandleFunc(testParamsHandler).Name("user.show")
+	router.GET("/users/:id/action/:action").HandleFunc(testParamsHandler).Name("user.action")
+	router.GET("/colors/$color").HandleFunc(testHandler).Name("color")
+	router.GET("/assets/*path").HandleFunc(testHandler).Name("assets")
+	router.GET("/products/:id(^[0-9]+$)").HandleFunc(testHandler)
+	router.GET("/files/:name([a-z-]+)").HandleFunc(testHandler)
 	// route groups
 	apiGroup := router.Group("/api")
 	v1Group := apiGroup.Group("/v1")
 	v1Group.GET("/").HandleFunc(testHandler)
 	v1Group.POST("/").HandleFunc(testHandler)
-	v1Group.GET("/stats").HandleFunc(testHandler)
+	v1Group.GET("/stats").HandleFunc(testHandler).Name("stats")
 	apiDocsGroup := v1Group.Group("/docs")
 	apiDocsGroup.GET("/stats").HandleFunc(testHandler)
 	// chain tests
@@ -11,18 +11,28 @@ package goro
 
 import (
 	"fmt"
+	"net/http"
 	"regexp"
 	"strings"
 )
 
 // Node - tree node to store route information
 type Node struct {
-	part     string
-	nodeType RouteComponentType
-	regexp   *regexp.Regexp // Not used currently
-	routes   map[string]*Route
-	nodes    []*Node
-	parent   *Node
+	part      string
+	nodeType  RouteComponentType
+	paramName string
+	paramType ParamType
+	regexp    *regexp.Regexp
+	routes    map[string][]*Route
+	nodes     []*Node
+	parent    *Node
+	// pattern - the parameters and fixed text of a wildcard part that contains fixed
+	// text (e.g.: ':name.:ext')
+	pattern *segmentPattern
+	// optionalRoute - set if the node was only added for a route with optional parts
+	// (e.g.: '/docs/:lang?/:page'). the sibling wildcards created for the variants of
+	// a single route are not reported as ambiguous
+	optionalRoute *Route
 }
 
 // Tree - storage for routes
@@ -37,21 +47,56 @@ func NewTree() *Tree {
 	}
 }
 
-// NewNode - creates a new Node instance and appends it to the tree
+// NewNode - creates a new Node instance and appends it to the tree. Wildcard parts
+// may declare a type in angle brackets (e.g.: ':id<int>') and / or a regular
+// expression constraint in parentheses (e.g.: ':id(^[0-9]+$)'). Constraints are
+// anchored so that they must match the entire segment. Parts may also combine
+// wildcards with fixed text (e.g.: ':name.:ext' or ':from-:to'). Catch-all parts
+// starting with '*' match zero or more segments, parts starting with '+' match one
+// or more. Catch-alls can be named (e.g.: '+path') to expose the matched value.
 func (t *Tree) NewNode(part string, parent *Node) *Node {
 	nodeType := ComponentTypeFixed
-	if strings.HasPrefix(part, "*") {
+	paramName := ""
+	paramType := ParamTypeString
+	var partRegexp *regexp.Regexp
+	var pattern *segmentPattern
+	if isCatchAllPart(part) {
 		nodeType = ComponentTypeCatchAll
+		paramName = strings.TrimLeft(part, "*+")
+	} else if isPatternPart(part) {
+		nodeType = ComponentTypeWildcard
+		parsed, parseErr := parseSegmentPattern(part)
+		if parseErr != nil {
+			panic(parseErr.Error())
+		}
+		pattern = parsed
 	} else if strings.HasPrefix(part, ":") {
 		nodeType = ComponentTypeWildcard
+		name, typeName, pattern := splitWildcardPart(part)
+		paramName = name
+		resolvedType, isKnownType := ParamTypeForName(typeName)
+		if !isKnownType {
+			panic(fmt.Sprintf("Unknown wildcard type '%s'. part='%s'", typeName, part))
+		}
+		paramType = resolvedType
+		if pattern != "" {
+			compiled, compileErr := regexp.Compile("^(?:" + pattern + ")$")
+			if compileErr != nil {
+				panic(fmt.Sprintf("Invalid wildcard constraint '%s'. error='%s'", part, compileErr))
+			}
+			partRegexp = compiled
+		}
 	}
 	node := &Node{
-		part:     part,
-		nodeType: nodeType,
-		regexp:   nil,
-		nodes:    []*Node{},
-		parent:   parent,
-		routes:   nil,
+		part:      part,
+		nodeType:  nodeType,
+		paramName: paramName,
+		paramType: paramType,
+		regexp:    partRegexp,
+		pattern:   pattern,
+		nodes:     []*Node{},
+		parent:    parent,
+		routes:    nil,
 	}
 	if parent == nil {
 		t.nodes = append(t.nodes, node)
@@ -61,55 +106,148 @@ func (t *Tree) NewNode(part string, parent *Node) *Node {
 	return node
 }
 
-// AddRouteToTree - splits the route into Nodes and adds them to the tree
-func (t *Tree) AddRouteToTree(route *Route, variables map[string]string) {
-	path := route.PathFormat
-	deslashedPath := path
-	if strings.HasPrefix(deslashedPath, "/") {
-		deslashedPath = path[1:]
-	}
-	split := strings.Split(deslashedPath, "/")
+// AddRouteToTree - splits the route into Nodes and adds them to the tree. If the
+// route cannot be added (e.g.: a variable cannot be resolved) a RegistrationError
+// is returned and the tree is left unchanged. Multiple routes can be registered for
+// the same method and path (see Route.Headers etc.); duplicates are reported by
+// Router.Validate. Routes with optional wildcards (e.g.: '/docs/:lang?/:page') are
+// added once for each combination of the optional parts being present or absent.
+func (t *Tree) AddRouteToTree(route *Route, variables map[string]string) error {
+	_, addErr := t.addRoute(route, variables)
+	return addErr
+}
 
-	if route.IsRoot() {
-		node := t.NewNode("/", nil)
-		if node.routes == nil {
-			node.routes = map[string]*Route{}
+// addRoute - adds the route to the tree (see AddRouteToTree) and returns the nodes
+// that the route was added at
+func (t *Tree) addRoute(route *Route, variables map[string]string) ([]*Node, error) {
+	for _, method := range route.Methods() {
+		if !isValidMethod(method) {
+			return nil, NewRegistrationError(RegistrationErrorInvalidMethod, route,
+				fmt.Sprintf("invalid method '%s'", method))
 		}
-		node.routes[route.Method] = route
-	} else {
-		// check to see if we need to do any variable substitution before parsing
-		// NOTE: does not support nested variables
-		var processedSplit []string
-		for _, component := range split {
-			if isVariablePart(component) {
-				deslashedVar := resolveVariable(component, variables, path)
-				if strings.HasPrefix(deslashedVar, "/") {
-					deslashedVar = deslashedVar[1:]
-				}
-				splitVar := strings.Split(deslashedVar, "/")
-				processedSplit = append(processedSplit, splitVar...)
-			} else {
-				processedSplit = append(processedSplit, component)
+	}
+	split, splitErr := t.splitRoutePath(route, variables)
+	if splitErr != nil {
+		return nil, splitErr
+	}
+	expanded := expandOptionalParts(split)
+	nodes := make([]*Node, len(expanded))
+	for idx, parts := range expanded {
+		nodes[idx] = t.addRouteAtParts(route, parts, len(expanded) > 1)
+	}
+	return nodes, nil
+}
+
+// addRouteAtParts - adds the route (for each of its methods) to the node for the
+// parts, creating any nodes that do not exist, and returns the node. optional should
+// be true if the parts are one of the variants of a route with optional parts
+func (t *Tree) addRouteAtParts(route *Route, parts []string, optional bool) *Node {
+	var parentNode *Node
+	var node *Node
+	for _, component := range parts {
+		// get an existing node for this segment or attach to the tree
+		node = t.nodeForExactPart(component, parentNode)
+		if node == nil {
+			node = t.NewNode(component, parentNode)
+			if optional {
+				node.optionalRoute = route
 			}
+		} else if node.optionalRoute != route {
+			node.optionalRoute = nil
+		}
+		parentNode = node
+	}
+	if node.routes == nil {
+		node.routes = map[string][]*Route{}
+	}
+	for _, method := range route.Methods() {
+		if !containsRoute(node.routes[method], route) {
+			node.routes[method] = append(node.routes[method], route)
 		}
+	}
+	return node
+}
 
-		split = processedSplit
+// expandOptionalParts - returns every combination of the parts with the optional
+// wildcards either included (without the '?' suffix) or left out. If all of the
+// parts are left out, the root path is used
+func expandOptionalParts(parts []string) [][]string {
+	expanded := [][]string{{}}
+	for _, part := range parts {
+		if !isOptionalPart(part) {
+			for idx := range expanded {
+				expanded[idx] = append(expanded[idx], part)
+			}
+			continue
+		}
+		required := strings.TrimSuffix(part, "?")
+		withPart := make([][]string, 0, len(expanded)*2)
+		for _, variant := range expanded {
+			included := append(append([]string{}, variant...), required)
+			withPart = append(withPart, included, variant)
+		}
+		expanded = withPart
+	}
+	for idx, variant := range expanded {
+		if len(variant) == 0 {
+			expanded[idx] = []string{RootPath}
+		}
+	}
+	return expanded
+}
 
-		var parentNode *Node
-		var node *Node
-		for _, component := range split {
-			// get an existing node for this segment or attach to the tree
-			node = t.nodeForExactPart(component, parentNode)
-			if node == nil {
-				node = t.NewNode(component, parentNode)
+// splitRoutePath - splits the route path into parts, substituting any variables,
+// and checks that each of the parts can be parsed
+func (t *Tree) splitRoutePath(route *Route, variables map[string]string) ([]string, error) {
+	if route.IsRoot() {
+		return []string{RootPath}, nil
+	}
+	if constraintContainsSlash(route.PathFormat) {
+		return nil, NewRegistrationError(RegistrationErrorInvalidPart, route,
+			"wildcard types and constraints cannot contain '/' as they only apply to a single segment")
+	}
+	deslashedPath := strings.TrimSuffix(strings.TrimPrefix(route.PathFormat, "/"), "/")
+	// check to see if we need to do any variable substitution before parsing
+	var processedSplit []string
+	for _, component := range strings.Split(deslashedPath, "/") {
+		if isVariablePart(component) {
+			deslashedVar, resolveErr := resolveVariableComponent(component, variables)
+			if resolveErr != nil {
+				return nil, NewRegistrationError(RegistrationErrorUnresolvedVariable, route, resolveErr.Error())
 			}
-			parentNode = node
+			deslashedVar = strings.TrimPrefix(deslashedVar, "/")
+			processedSplit = append(processedSplit, strings.Split(deslashedVar, "/")...)
+		} else {
+			processedSplit = append(processedSplit, component)
+		}
+	}
+	for _, component := range processedSplit {
+		if partErr := validatePart(component); partErr != nil {
+			return nil, NewRegistrationError(RegistrationErrorInvalidPart, route, partErr.Error())
 		}
-		if node.routes == nil {
-			node.routes = map[string]*Route{}
+	}
+	return processedSplit, nil
+}
+
+// constraintContainsSlash - returns true if a wildcard type or constraint in the path
+// (e.g.: ':p(a/b)') contains a '/'
+func constraintContainsSlash(routePath string) bool {
+	depth := 0
+	for idx := 0; idx < len(routePath); idx++ {
+		switch routePath[idx] {
+		case '(', '<':
+			depth++
+		case ')', '>':
+			if depth > 0 {
+				depth--
+			}
+		case '/':
+			if depth > 0 {
+				return true
+			}
 		}
-		node.routes[route.Method] = route
 	}
+	return false
 }
 
 // nodeForExactPart - finds a Node either in the top-level Tree nodes or in the
@@ -136,12 +274,81 @@ func (node *Node) HasChildren() bool {
 }
 
 // RouteForMethod - returns the route that was defined for the method or nil if
-// no route is defined
+// no route is defined. If there are multiple routes for the method, the route
+// without request conditions is preferred
 func (node *Node) RouteForMethod(method string) *Route {
-	if node.routes != nil && len(node.routes) > 0 {
-		return node.routes[strings.ToUpper(method)]
+	if route := node.routeForMethodKey(strings.ToUpper(method)); route != nil {
+		return route
 	}
-	return nil
+	return node.routeForMethodKey(MethodAny)
+}
+
+// routeForMethodKey - returns the last registered enabled route for the method key,
+// preferring routes without request conditions
+func (node *Node) routeForMethodKey(method string) *Route {
+	var conditional *Route
+	methodRoutes := node.routes[method]
+	for idx := len(methodRoutes) - 1; idx >= 0; idx-- {
+		route := methodRoutes[idx]
+		if route.IsDisabled() {
+			continue
+		}
+		if !route.HasConditions() {
+			return route
+		}
+		if conditional == nil {
+			conditional = route
+		}
+	}
+	return conditional
+}
+
+// RouteForRequest - returns the route for the method whose request conditions are
+// satisfied by the request. Routes with conditions are checked in registration
+// order before the route without conditions (if any). If there are routes for the
+// method but none can handle the request, the route will be nil and the status code
+// describes the failure (e.g.: 406 or 415). If there are no routes for the method
+// both values will be empty
+func (node *Node) RouteForRequest(method string, req *http.Request) (*Route, int) {
+	failedStatus := 0
+	for _, methodKey := range []string{strings.ToUpper(method), MethodAny} {
+		var fallback *Route
+		for _, route := range node.routes[methodKey] {
+			if route.IsDisabled() {
+				continue
+			}
+			if !route.HasConditions() {
+				fallback = route
+				continue
+			}
+			status := route.checkConditions(req)
+			if status == 0 {
+				return route, 0
+			}
+			if failedStatus == 0 || conditionFailurePriority(status) > conditionFailurePriority(failedStatus) {
+				failedStatus = status
+			}
+		}
+		if fallback != nil {
+			return fallback, 0
+		}
+	}
+	return nil, failedStatus
+}
+
+// isValidMethod - returns true if the method is a valid HTTP method token (e.g.:
+// 'GET' or a custom method such as 'PROPFIND'). MethodAny is also valid
+func isValidMethod(method string) bool {
+	if method == "" {
+		return false
+	}
+	for idx := 0; idx < len(method); idx++ {
+		char := method[idx]
+		if !isParamNameByte(char) && !strings.ContainsRune("!#$%&'*+-.^`|~", rune(char)) {
+			return false
+		}
+	}
+	return true
 }
 
 // isVariablePart - is the string (part) a variable part
@@ -154,9 +361,77 @@ func isWildcardPart(part string) bool {
 	return strings.HasPrefix(part, ":")
 }
 
+// isOptionalPart - is the string (part) a wildcard part that may be left out of
+// the path (e.g.: ':lang?')
+func isOptionalPart(part string) bool {
+	return isWildcardPart(part) && !isPatternPart(part) && strings.HasSuffix(part, "?")
+}
+
+// splitWildcardPart - splits a wildcard part into the parameter name, the
+// (optional) type name and the (optional) regular expression constraint. For
+// example, ':id<int>(^[0-9]+$)' => "id", "int", "^[0-9]+$"
+func splitWildcardPart(part string) (name string, typeName string, pattern string) {
+	name = strings.TrimSuffix(strings.TrimPrefix(part, ":"), "?")
+	openIdx := strings.Index(name, "(")
+	if openIdx != -1 && strings.HasSuffix(name, ")") {
+		pattern = name[openIdx+1 : len(name)-1]
+		name = name[:openIdx]
+	}
+	typeIdx := strings.Index(name, "<")
+	if typeIdx != -1 && strings.HasSuffix(name, ">") {
+		typeName = name[typeIdx+1 : len(name)-1]
+		name = name[:typeIdx]
+	}
+	return name, typeName, pattern
+}
+
+// validatePart - checks that a wildcard part has a known type and a valid constraint
+func validatePart(part string) error {
+	if isPatternPart(part) {
+		_, parseErr := parseSegmentPattern(part)
+		return parseErr
+	}
+	if !isWildcardPart(part) {
+		return nil
+	}
+	_, typeName, pattern := splitWildcardPart(part)
+	if _, isKnownType := ParamTypeForName(typeName); !isKnownType {
+		return fmt.Errorf("Unknown wildcard type '%s'. part='%s'", typeName, part)
+	}
+	if pattern != "" {
+		if _, compileErr := regexp.Compile("^(?:" + pattern + ")$"); compileErr != nil {
+			return fmt.Errorf("Invalid wildcard constraint '%s'. error='%s'", part, compileErr)
+		}
+	}
+	return nil
+}
+
+// acceptsValue - returns true if the value satisfies the Node's constraint and type
+func (node *Node) acceptsValue(value string) bool {
+	if node.regexp != nil && !node.regexp.MatchString(value) {
+		return false
+	}
+	return node.paramType.Accepts(value)
+}
+
+// ParamValue - validates the value against the Node's constraint and type and
+// returns the converted value. ok will be false if the value is not acceptable
+func (node *Node) ParamValue(value string) (converted interface{}, ok bool) {
+	if node.regexp != nil && !node.regexp.MatchString(value) {
+		return nil, false
+	}
+	return node.paramType.Convert(value)
+}
+
 // isCatchAllPart - is the string (part) a catch-all part
 func isCatchAllPart(part string) bool {
-	return strings.HasPrefix(part, "*")
+	return strings.HasPrefix(part, "*") || strings.HasPrefix(part, "+")
+}
+
+// matchesEmptyRemainder - returns true if the node is a catch-all that also matches
+// when there is nothing left of the path (i.e.: a '*' rather than a '+' catch-all)
+func (node *Node) matchesEmptyRemainder() bool {
+	return node.nodeType == ComponentTypeCatchAll && strings.HasPrefix(node.part, "*")
 }
 
 // containsVariablePrefix - does the string contain a variable prefix value
@@ -164,10 +439,9 @@ func containsVariablePrefix(s string) bool {
 	return strings.Contains(s, "$")
 }
 
-// resolveVariable - returns a string with all variables resolved.
-// Takes in a string which may contain variables, a map 'variables' used
-// for lookup, and a string 'path' to construct panic statement if lookup fails.
-func resolveVariable(component string, variables map[string]string, path string) string {
+// resolveVariableComponent - returns a string with all variables resolved or an
+// error if a variable has no value definition
+func resolveVariableComponent(component string, variables map[string]string) (string, error) {
 	resolved := ""
 	parts := splitVariableComponent(component)
 	for _, part := range parts {
@@ -177,12 +451,15 @@ func resolveVariable(component string, variables map[string]string, path string)
 			if containsVariablePrefix(dsp) {
 				lookup := variables[dsp]
 				if lookup == "" {
-					// we couldn't substitute the requested variable as there is no value definition
-					panic(fmt.Sprintf("Missing variable substitution for '%s'. route='%s'", dsp, path))
+					return "", fmt.Errorf("Missing variable substitution for '%s'", dsp)
 				}
 				// Another lookup is required because value definition contains a variable.
 				if containsVariablePrefix(lookup) {
-					lookup = resolveVariable(lookup, variables, path)
+					nestedLookup, nestedErr := resolveVariableComponent(lookup, variables)
+					if nestedErr != nil {
+						return "", nestedErr
+					}
+					lookup = nestedLookup
 				}
 				deslashedPart[i] = lookup
 			}
@@ -191,7 +468,7 @@ func resolveVariable(component string, variables map[string]string, path string)
 		resolvedPart := strings.Join(deslashedPart, "/")
 		resolved = strings.Join([]string{resolved, resolvedPart}, "")
 	}
-	return resolved
+	return resolved, nil
 }
 
 // splitVariableComponent - Splits a string into variables, and non-variable
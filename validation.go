@@ -0,0 +1,381 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidatorFunc - checks a field value against a validation rule. The param is the
+// text after '=' in the rule (e.g.: '5' for 'min=5'). Pointer values are passed
+// dereferenced. A non-nil error describes why the value is invalid
+type ValidatorFunc func(value interface{}, param string) error
+
+// builtinValidators - the validation rules that are always available. 'required' and
+// 'omitempty' are handled separately as they apply to missing values
+var builtinValidators = map[string]func(value reflect.Value, param string) error{
+	"min":   validateMin,
+	"max":   validateMax,
+	"len":   validateLen,
+	"email": validateEmail,
+	"oneof": validateOneOf,
+}
+
+// AddValidator registers a custom validation rule that can be used in validate tags
+// (e.g.: `validate:"required,slug"`). Custom rules take precedence over the built-in
+// rules with the same name
+func (r *Router) AddValidator(name string, validator ValidatorFunc) {
+	r.validators[name] = validator
+}
+
+// Validate checks the struct that v points to using the validate tags of its fields:
+//
+//	required  - the value must not be the zero value (or a nil pointer)
+//	omitempty - skip the remaining rules if the value is the zero value
+//	min=N     - numbers must be at least N, strings and slices must have a length of
+//	            at least N
+//	max=N     - as min, but at most N
+//	len=N     - strings and slices must have a length of exactly N
+//	email     - the value must be an email address
+//	oneof=a b - the value must be one of the space separated values
+//
+// Rules, other than required, are not checked for nil pointers. Nested structs are
+// validated. All problems are returned as FieldErrors and recorded in Errors (with a
+// 422 status code). Custom rules can be added using Router.AddValidator. An unknown
+// rule, or a rule with an invalid parameter, is returned as a plain error
+func (hc *HandlerContext) Validate(v interface{}) error {
+	fieldErrs, validateErr := hc.validate(v)
+	if validateErr != nil {
+		return validateErr
+	}
+	if len(fieldErrs) == 0 {
+		return nil
+	}
+	hc.Errors = append(hc.Errors, validationRoutingError(fieldErrs))
+	return fieldErrs
+}
+
+// BindAndValidate binds the request to dst (see Bind) and then validates it (see
+// Validate). If binding or validation fails, the error is emitted using the router
+// error handlers (400 for binding problems, 422 for validation problems) and
+// returned, so the handler should return without writing a response. If no error
+// handler has been set for the status code, the field errors are written as JSON:
+//
+//	{"status": 422, "message": "...", "errors": [{"field": "Name", ...}]}
+func (hc *HandlerContext) BindAndValidate(dst interface{}) error {
+	fieldErrs, bindErr := hc.bind(dst)
+	if bindErr != nil {
+		return bindErr
+	}
+	if len(fieldErrs) > 0 {
+		hc.emitFieldErrors(bindRoutingError(fieldErrs))
+		return fieldErrs
+	}
+	fieldErrs, validateErr := hc.validate(dst)
+	if validateErr != nil {
+		return validateErr
+	}
+	if len(fieldErrs) > 0 {
+		hc.emitFieldErrors(validationRoutingError(fieldErrs))
+		return fieldErrs
+	}
+	return nil
+}
+
+// emitFieldErrors - emits the error using the router (if any)
+func (hc *HandlerContext) emitFieldErrors(routingError RoutingError) {
+	if hc.router == nil {
+		hc.Errors = append(hc.Errors, routingError)
+		return
+	}
+	hc.router.emitRoutingError(hc, routingError)
+}
+
+// validationRoutingError - creates the RoutingError used to report validation
+// problems
+func validationRoutingError(fieldErrs FieldErrors) RoutingError {
+	return RoutingError{
+		StatusCode: http.StatusUnprocessableEntity,
+		ErrorCode:  ValidationErrorCode,
+		Error:      fieldErrs,
+		Message:    "the request failed validation",
+		Info:       ErrorInfoMap{"fields": fieldErrs},
+	}
+}
+
+// validate - validates the struct that v points to and returns the field errors. an
+// error is returned if v is not a struct (or a pointer to one) or a rule is invalid
+func (hc *HandlerContext) validate(v interface{}) (FieldErrors, error) {
+	target := reflect.ValueOf(v)
+	for target.Kind() == reflect.Ptr && !target.IsNil() {
+		target = target.Elem()
+	}
+	if target.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Validate requires a struct but got %T", v)
+	}
+	return validateStruct(target, "", hc.router)
+}
+
+// validateStruct - validates the fields of the struct (and any nested structs). the
+// prefix is prepended to the field names of nested structs. stops at the first
+// invalid rule
+func validateStruct(structValue reflect.Value, prefix string, router *Router) (FieldErrors, error) {
+	var fieldErrs FieldErrors
+	structType := structValue.Type()
+	for fieldIdx := 0; fieldIdx < structType.NumField(); fieldIdx++ {
+		field := structType.Field(fieldIdx)
+		fieldValue := structValue.Field(fieldIdx)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			embeddedErrs, ruleErr := validateStruct(fieldValue, prefix, router)
+			if ruleErr != nil {
+				return nil, ruleErr
+			}
+			fieldErrs = append(fieldErrs, embeddedErrs...)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+		if rules := field.Tag.Get("validate"); rules != "" && rules != "-" {
+			fieldErr, failed, ruleErr := validateField(field, fieldValue, rules, router)
+			if ruleErr != nil {
+				return nil, fmt.Errorf("%s. field='%s%s'", ruleErr, prefix, field.Name)
+			}
+			if failed {
+				fieldErr.Field = prefix + fieldErr.Field
+				fieldErrs = append(fieldErrs, fieldErr)
+				continue
+			}
+		}
+		nested := fieldValue
+		if nested.Kind() == reflect.Ptr && !nested.IsNil() {
+			nested = nested.Elem()
+		}
+		if nested.Kind() == reflect.Struct && nested.Type() != timeType && nested.Type() != uuidType {
+			nestedErrs, ruleErr := validateStruct(nested, prefix+field.Name+".", router)
+			if ruleErr != nil {
+				return nil, ruleErr
+			}
+			fieldErrs = append(fieldErrs, nestedErrs...)
+		}
+	}
+	return fieldErrs, nil
+}
+
+// validateField - checks the value against each of the rules, stopping at the first
+// rule that fails. an error is returned if a rule is invalid
+func validateField(field reflect.StructField, fieldValue reflect.Value, rules string,
+	router *Router) (FieldError, bool, error) {
+	source, key := fieldSource(field)
+	for _, rule := range strings.Split(rules, ",") {
+		name, param := rule, ""
+		if equalsIdx := strings.Index(rule, "="); equalsIdx != -1 {
+			name, param = rule[:equalsIdx], rule[equalsIdx+1:]
+		}
+		var ruleErr error
+		switch {
+		case name == "required":
+			if isZeroValue(fieldValue) {
+				ruleErr = errors.New("is required")
+			}
+		case name == "omitempty":
+			if isZeroValue(fieldValue) {
+				return FieldError{}, false, nil
+			}
+		case fieldValue.Kind() == reflect.Ptr && fieldValue.IsNil():
+			return FieldError{}, false, nil
+		default:
+			ruleErr = applyRule(name, param, reflect.Indirect(fieldValue), router)
+		}
+		if invalidErr, isInvalid := ruleErr.(invalidRuleError); isInvalid {
+			return FieldError{}, false, invalidErr
+		}
+		if ruleErr != nil {
+			return FieldError{
+				Field:   field.Name,
+				Source:  source,
+				Key:     key,
+				Value:   fieldValueString(fieldValue),
+				Rule:    name,
+				Message: ruleErr.Error(),
+			}, true, nil
+		}
+	}
+	return FieldError{}, false, nil
+}
+
+// applyRule - checks the value using the custom (checking the parent routers if the
+// router has been mounted) or built-in rule. returns an invalidRuleError if the rule
+// does not exist
+func applyRule(name string, param string, value reflect.Value, router *Router) error {
+	for ; router != nil; router = router.parent {
+		if validator, ok := router.validators[name]; ok {
+			return validator(value.Interface(), param)
+		}
+	}
+	if validator, ok := builtinValidators[name]; ok {
+		return validator(value, param)
+	}
+	return invalidRuleError(fmt.Sprintf("Unknown validation rule '%s'", name))
+}
+
+// invalidRuleError - a problem with a validation rule itself (rather than with the
+// value being validated)
+type invalidRuleError string
+
+// Error - implement the error interface
+func (ire invalidRuleError) Error() string {
+	return string(ire)
+}
+
+// fieldSource - returns where the value of the field is bound from and its key (used
+// to describe validation errors in terms of the request)
+func fieldSource(field reflect.StructField) (source string, key string) {
+	for _, bindSource := range bindSources {
+		if tagKey := field.Tag.Get(bindSource); tagKey != "" && tagKey != "-" {
+			return bindSource, tagKey
+		}
+	}
+	for _, tagName := range []string{"json", "xml"} {
+		if tagKey := strings.Split(field.Tag.Get(tagName), ",")[0]; tagKey != "" && tagKey != "-" {
+			return BindSourceBody, tagKey
+		}
+	}
+	return BindSourceBody, field.Name
+}
+
+// fieldValueString - returns the string representation of simple values (empty for
+// other values)
+func fieldValueString(value reflect.Value) string {
+	value = reflect.Indirect(value)
+	switch value.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return fmt.Sprint(value.Interface())
+	}
+	return ""
+}
+
+// validateMin - numbers must be at least the param, other values must have a length
+// of at least the param
+func validateMin(value reflect.Value, param string) error {
+	number, isNumber, limitErr := compareValue(value, param)
+	if limitErr != nil {
+		return limitErr
+	}
+	limit, _ := strconv.ParseFloat(param, 64)
+	if number < limit {
+		if isNumber {
+			return fmt.Errorf("must be at least %s", param)
+		}
+		return fmt.Errorf("must have a length of at least %s", param)
+	}
+	return nil
+}
+
+// validateMax - numbers must be at most the param, other values must have a length
+// of at most the param
+func validateMax(value reflect.Value, param string) error {
+	number, isNumber, limitErr := compareValue(value, param)
+	if limitErr != nil {
+		return limitErr
+	}
+	limit, _ := strconv.ParseFloat(param, 64)
+	if number > limit {
+		if isNumber {
+			return fmt.Errorf("must be at most %s", param)
+		}
+		return fmt.Errorf("must have a length of at most %s", param)
+	}
+	return nil
+}
+
+// validateLen - the value must have a length of exactly the param
+func validateLen(value reflect.Value, param string) error {
+	length, isNumber, limitErr := compareValue(value, param)
+	if limitErr != nil {
+		return limitErr
+	}
+	if isNumber {
+		return fmt.Errorf("len cannot be used with %s values", value.Kind())
+	}
+	if expected, _ := strconv.ParseFloat(param, 64); length != expected {
+		return fmt.Errorf("must have a length of %s", param)
+	}
+	return nil
+}
+
+// compareValue - returns the number (or length) to compare with the param. returns
+// an invalidRuleError if the param is not a number
+func compareValue(value reflect.Value, param string) (number float64, isNumber bool, err error) {
+	if _, parseErr := strconv.ParseFloat(param, 64); parseErr != nil {
+		return 0, false, invalidRuleError(fmt.Sprintf("Invalid validation rule parameter '%s'", param))
+	}
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), true, nil
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true, nil
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String())), false, nil
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(value.Len()), false, nil
+	}
+	return 0, false, fmt.Errorf("cannot be compared with %s", param)
+}
+
+// validateEmail - the value must be a plain email address (e.g.: 'name@example.com')
+func validateEmail(value reflect.Value, _ string) error {
+	if value.Kind() == reflect.String {
+		if address, parseErr := mail.ParseAddress(value.String()); parseErr == nil && address.Address == value.String() {
+			return nil
+		}
+	}
+	return errors.New("must be a valid email address")
+}
+
+// validateOneOf - the value must be one of the space separated values in the param
+func validateOneOf(value reflect.Value, param string) error {
+	allowed := strings.Fields(param)
+	if !containsString(allowed, fmt.Sprint(value.Interface())) {
+		return fmt.Errorf("must be one of [%s]", strings.Join(allowed, ", "))
+	}
+	return nil
+}
+
+// fieldErrorsResponse - the JSON representation of field errors
+type fieldErrorsResponse struct {
+	Status  int         `json:"status"`
+	Message string      `json:"message"`
+	Errors  FieldErrors `json:"errors"`
+}
+
+// writeFieldErrors - writes the field errors as JSON
+func writeFieldErrors(w http.ResponseWriter, statusCode int, message string, fieldErrs FieldErrors) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(fieldErrorsResponse{
+		Status:  statusCode,
+		Message: message,
+		Errors:  fieldErrs,
+	})
+}
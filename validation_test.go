@@ -0,0 +1,146 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/theyakka/goro"
+)
+
+type address struct {
+	City string `json:"city" validate:"required"`
+}
+
+type createAccountRequest struct {
+	Name    string   `json:"name" validate:"required,min=2,max=20"`
+	Email   string   `json:"email" validate:"required,email"`
+	Plan    string   `json:"plan" validate:"oneof=free pro"`
+	Age     *int     `json:"age" validate:"min=18"`
+	Handle  string   `json:"handle" validate:"omitempty,slug"`
+	Tags    []string `json:"tags" validate:"max=2"`
+	Page    int      `query:"page" validate:"min=1,max=100"`
+	Address address  `json:"address"`
+}
+
+func TestBindAndValidate(t *testing.T) {
+	handled := false
+	validationRouter := goro.NewRouter()
+	validationRouter.AddValidator("slug", func(value interface{}, _ string) error {
+		if strings.ContainsAny(value.(string), " /") {
+			return errors.New("must be a slug")
+		}
+		return nil
+	})
+	validationRouter.POST("/accounts").HandleFunc(func(ctx *goro.HandlerContext) {
+		var account createAccountRequest
+		if ctx.BindAndValidate(&account) != nil {
+			return
+		}
+		handled = true
+		ctx.ResponseWriter.WriteHeader(http.StatusCreated)
+	})
+	postAccount := func(query string, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/accounts"+query, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		validationRouter.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	recorder := postAccount("?page=1", `{"name":"Bo","email":"bo@example.com","plan":"pro","address":{"city":"Perth"}}`)
+	if recorder.Code != http.StatusCreated || !handled {
+		t.Error("Expected a valid request to be handled but got", recorder.Code, recorder.Body.String())
+	}
+
+	recorder = postAccount("?page=0", `{"name":"B","email":"bo","plan":"gold","age":16,"handle":"a b","tags":["a","b","c"]}`)
+	if recorder.Code != http.StatusUnprocessableEntity {
+		t.Fatal("Expected 422 but got", recorder.Code)
+	}
+	var response struct {
+		Status int               `json:"status"`
+		Errors []goro.FieldError `json:"errors"`
+	}
+	if decodeErr := json.NewDecoder(recorder.Body).Decode(&response); decodeErr != nil {
+		t.Fatal("Expected a JSON response but got", decodeErr)
+	}
+	rules := map[string]string{}
+	for _, fieldErr := range response.Errors {
+		rules[fieldErr.Field] = fieldErr.Rule
+	}
+	expectedRules := map[string]string{"Name": "min", "Email": "email", "Plan": "oneof", "Age": "min",
+		"Handle": "slug", "Tags": "max", "Page": "min", "Address.City": "required"}
+	if response.Status != http.StatusUnprocessableEntity || len(rules) != len(expectedRules) {
+		t.Error("Expected", len(expectedRules), "field errors but got", response.Errors)
+	}
+	for field, rule := range expectedRules {
+		if rules[field] != rule {
+			t.Error("Expected field", field, "to fail", rule, "but got", rules[field])
+		}
+	}
+	for _, fieldErr := range response.Errors {
+		if fieldErr.Field == "Page" && (fieldErr.Source != goro.BindSourceQuery || fieldErr.Key != "page") {
+			t.Error("Expected the page error to reference the query parameter but got", fieldErr)
+		}
+	}
+
+	recorder = postAccount("?page=first", `{}`)
+	if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), `"source":"query"`) {
+		t.Error("Expected a 400 for a bind error but got", recorder.Code, recorder.Body.String())
+	}
+
+	var handledErr goro.RoutingError
+	validationRouter.SetErrorHandler(http.StatusUnprocessableEntity, goro.ContextHandlerFunc(func(ctx *goro.HandlerContext) {
+		handledErr = ctx.ErrorForStatus(http.StatusUnprocessableEntity)
+		ctx.ResponseWriter.WriteHeader(http.StatusTeapot)
+	}))
+	recorder = postAccount("?page=1", `{"name":"Bo","email":"bo@example.com"}`)
+	if recorder.Code != http.StatusTeapot || handledErr.ErrorCode != goro.ValidationErrorCode {
+		t.Error("Expected the custom error handler to be used but got", recorder.Code, handledErr)
+	}
+}
+
+func TestValidateInvalidRules(t *testing.T) {
+	validateErrs := map[string]error{}
+	ruleRouter := goro.NewRouter()
+	ruleRouter.GET("/rules").HandleFunc(func(ctx *goro.HandlerContext) {
+		validateErrs["unknown"] = ctx.Validate(&struct {
+			Name string `validate:"required,slug"`
+		}{Name: "bo"})
+		validateErrs["parameter"] = ctx.Validate(&struct {
+			Address struct {
+				City string `validate:"min=two"`
+			}
+		}{})
+	})
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/rules", nil)
+	ruleRouter.ServeHTTP(recorder, req)
+	// invalid rules are returned as errors instead of crashing the request
+	expectedMessages := map[string]string{
+		"unknown":   "Unknown validation rule 'slug'. field='Name'",
+		"parameter": "Invalid validation rule parameter 'two'. field='Address.City'",
+	}
+	for kind, message := range expectedMessages {
+		validateErr := validateErrs[kind]
+		if _, isFieldErrs := validateErr.(goro.FieldErrors); validateErr == nil || isFieldErrs ||
+			validateErr.Error() != message {
+			t.Error("Expected", message, "for the", kind, "rule but got", validateErr)
+		}
+	}
+	if recorder.Code != http.StatusOK {
+		t.Error("Expected the request to complete but got", recorder.Code)
+	}
+}
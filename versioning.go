@@ -0,0 +1,261 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultVersionHeader - the default request header used to select an API version
+const DefaultVersionHeader = "X-API-Version"
+
+// Versioned - dispatches the requests under a prefix to the routes defined for the
+// requested API version. The version is selected using (in order of precedence):
+// a path segment (e.g.: '/api/v2/users'), the version header (e.g.: 'X-API-Version:
+// 2') or a vendor media type in the Accept header (e.g.: 'application/vnd.x.v2+json').
+// If the requested version doesn't define a route for the request, the nearest
+// lower version that does is used.
+type Versioned struct {
+	router         *Router
+	prefix         string
+	versions       []*APIVersion
+	header         string
+	vendor         string
+	defaultVersion int
+}
+
+// APIVersion - the routes for a single version of an API. Routes are registered
+// using the embedded Group
+type APIVersion struct {
+	*Group
+	number       int
+	router       *Router
+	deprecated   bool
+	deprecatedAt time.Time
+	sunsetAt     time.Time
+}
+
+// Versioned creates a versioned API at the prefix
+func (r *Router) Versioned(prefix string) *Versioned {
+	versioned := &Versioned{
+		router: r,
+		prefix: prefix,
+		header: DefaultVersionHeader,
+	}
+	route := newMountRoute(prefix, versioned)
+	route.Handler = versioned
+	r.Use(route)
+	return versioned
+}
+
+// Version returns the APIVersion for the version number, creating it if required
+func (v *Versioned) Version(number int) *APIVersion {
+	for _, version := range v.versions {
+		if version.number == number {
+			return version
+		}
+	}
+	versionRouter := NewRouter()
+	versionRouter.parent = v.router
+	versionRouter.variables = v.router.variables
+	versionRouter.routeMatcher.CaseSensitive = v.router.routeMatcher.CaseSensitive
+	version := &APIVersion{
+		Group:  NewGroup("", versionRouter),
+		number: number,
+		router: versionRouter,
+	}
+	version.Group.name = "v" + strconv.Itoa(number)
+	v.versions = append(v.versions, version)
+	sort.Slice(v.versions, func(i, j int) bool {
+		return v.versions[i].number < v.versions[j].number
+	})
+	return version
+}
+
+// SetVersionHeader sets the name of the request header used to select the version.
+// An empty name disables header based selection
+func (v *Versioned) SetVersionHeader(header string) *Versioned {
+	v.header = header
+	return v
+}
+
+// SetVendor sets the vendor name used in Accept header media types (e.g.: 'x' for
+// 'application/vnd.x.v2+json'). If not set, any vendor name will be accepted
+func (v *Versioned) SetVendor(vendor string) *Versioned {
+	v.vendor = strings.ToLower(vendor)
+	return v
+}
+
+// SetDefaultVersion sets the version used when the request doesn't specify one. If
+// not set, the latest version is used
+func (v *Versioned) SetDefaultVersion(number int) *Versioned {
+	v.defaultVersion = number
+	return v
+}
+
+// Number returns the version number
+func (av *APIVersion) Number() int {
+	return av.number
+}
+
+// Deprecate marks the version as deprecated. Responses will include a Deprecation
+// header (with the date, if not zero)
+func (av *APIVersion) Deprecate(at time.Time) *APIVersion {
+	av.deprecated = true
+	av.deprecatedAt = at
+	return av
+}
+
+// Sunset sets the date the version will stop being available. Responses will
+// include a Sunset header
+func (av *APIVersion) Sunset(at time.Time) *APIVersion {
+	av.sunsetAt = at
+	return av
+}
+
+// ServeHTTP - implement the http.Handler interface
+func (v *Versioned) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	ctx := NewHandlerContext(req, NewCheckedResponseWriter(w), v.router)
+	ctx.CatchAllValue = strings.TrimPrefix(req.URL.Path, "/")
+	v.Serve(ctx)
+}
+
+// Serve - implement the ContextHandler interface
+func (v *Versioned) Serve(ctx *HandlerContext) {
+	requested, requestPath := v.requestedVersion(ctx.Request, "/"+ctx.CatchAllValue)
+	method := strings.ToUpper(ctx.Request.Method)
+	var nearest *APIVersion
+	for idx := len(v.versions) - 1; idx >= 0; idx-- {
+		version := v.versions[idx]
+		if version.number > requested {
+			continue
+		}
+		if nearest == nil {
+			nearest = version
+		}
+		if version.router.definesRoute(method, requestPath, ctx.Request) {
+			nearest = version
+			break
+		}
+	}
+	if nearest == nil {
+		v.router.emitError(ctx, http.StatusNotFound, "Not Found", RouterGenericErrorCode, nil)
+		return
+	}
+	nearest.setHeaders(ctx.ResponseWriter.Header())
+	serveMountedRouter(ctx, nearest.router, requestPath)
+}
+
+// requestedVersion - returns the version requested and the path with any version
+// segment removed
+func (v *Versioned) requestedVersion(req *http.Request, requestPath string) (int, string) {
+	trimmedPath := strings.TrimPrefix(requestPath, "/")
+	segment := trimmedPath
+	if slashIdx := strings.IndexByte(trimmedPath, '/'); slashIdx != -1 {
+		segment = trimmedPath[:slashIdx]
+	}
+	if number, ok := parseVersion(segment, true); ok {
+		return number, "/" + strings.TrimPrefix(trimmedPath[len(segment):], "/")
+	}
+	if v.header != "" {
+		if number, ok := parseVersion(req.Header.Get(v.header), false); ok {
+			return number, requestPath
+		}
+	}
+	for _, entry := range ParseAccept(req.Header.Get("Accept")) {
+		if number, ok := v.vendorVersion(entry.Value); ok {
+			return number, requestPath
+		}
+	}
+	if v.defaultVersion != 0 {
+		return v.defaultVersion, requestPath
+	}
+	if len(v.versions) == 0 {
+		return 0, requestPath
+	}
+	return v.versions[len(v.versions)-1].number, requestPath
+}
+
+// vendorVersion - parses the version from a vendor media type (e.g.:
+// 'application/vnd.x.v2+json')
+func (v *Versioned) vendorVersion(mediaType string) (int, bool) {
+	vendorType := strings.TrimPrefix(mediaType, "application/vnd.")
+	if vendorType == mediaType {
+		return 0, false
+	}
+	if plusIdx := strings.IndexByte(vendorType, '+'); plusIdx != -1 {
+		vendorType = vendorType[:plusIdx]
+	}
+	dotIdx := strings.LastIndexByte(vendorType, '.')
+	if dotIdx == -1 || (v.vendor != "" && vendorType[:dotIdx] != v.vendor) {
+		return 0, false
+	}
+	return parseVersion(vendorType[dotIdx+1:], true)
+}
+
+// parseVersion - parses a version string (e.g.: 'v2' or '2'). if requirePrefix is
+// true then the 'v' prefix must be present
+func parseVersion(value string, requirePrefix bool) (int, bool) {
+	if len(value) > 1 && (value[0] == 'v' || value[0] == 'V') {
+		value = value[1:]
+	} else if requirePrefix {
+		return 0, false
+	}
+	number, parseErr := strconv.Atoi(value)
+	if parseErr != nil || number < 0 {
+		return 0, false
+	}
+	return number, true
+}
+
+// setHeaders - sets the deprecation / sunset headers for the version
+func (av *APIVersion) setHeaders(header http.Header) {
+	if av.deprecated {
+		deprecation := "true"
+		if !av.deprecatedAt.IsZero() {
+			deprecation = "@" + strconv.FormatInt(av.deprecatedAt.Unix(), 10)
+		}
+		header.Set("Deprecation", deprecation)
+	}
+	if !av.sunsetAt.IsZero() {
+		header.Set("Sunset", av.sunsetAt.UTC().Format(http.TimeFormat))
+	}
+}
+
+// label - describes the version when printing routes
+func (av *APIVersion) label() string {
+	label := "v" + strconv.Itoa(av.number)
+	if av.deprecated {
+		label += ", deprecated"
+	}
+	if !av.sunsetAt.IsZero() {
+		label += fmt.Sprintf(", sunset %s", av.sunsetAt.Format("2006-01-02"))
+	}
+	return label
+}
+
+// definesRoute - returns true if the router has a route for the method and path
+func (r *Router) definesRoute(method string, requestPath string, req *http.Request) bool {
+	match := r.routeMatcher.MatchPathToRoute(method, CleanPath(requestPath), req)
+	if match == nil {
+		return false
+	}
+	node := match.Node
+	r.routeMatcher.ReleaseMatch(match)
+	if node.RouteForMethod(method) != nil {
+		return true
+	}
+	return method == http.MethodHead && r.autoHead && node.RouteForMethod(http.MethodGet) != nil
+}
@@ -0,0 +1,110 @@
+// Goro
+//
+// Created by Yakka
+// http://theyakka.com
+//
+// Copyright (c) 2019 Yakka LLC.
+// All rights reserved.
+// See the LICENSE file for licensing details and requirements.
+
+package goro_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/theyakka/goro"
+)
+
+func newVersionedRouter(hitName *string) *goro.Router {
+	namedHandler := func(name string) goro.ContextHandlerFunc {
+		return func(ctx *goro.HandlerContext) {
+			*hitName = name
+		}
+	}
+	versionedRouter := goro.NewRouter()
+	api := versionedRouter.Versioned("/api").SetVendor("x")
+	v1 := api.Version(1).
+		Deprecate(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)).
+		Sunset(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	v1.GET("/users").HandleFunc(namedHandler("v1-users"))
+	v1.GET("/users/:id").HandleFunc(namedHandler("v1-user"))
+	v2 := api.Version(2)
+	v2.GET("/users").HandleFunc(namedHandler("v2-users"))
+	api.Version(3).GET("/orders").HandleFunc(namedHandler("v3-orders"))
+	return versionedRouter
+}
+
+func TestVersionedDispatch(t *testing.T) {
+	hitName := ""
+	versionedRouter := newVersionedRouter(&hitName)
+	requests := []struct {
+		path     string
+		headers  map[string]string
+		expected string
+	}{
+		{"/api/v1/users", nil, "v1-users"},
+		{"/api/v2/users", nil, "v2-users"},
+		{"/api/v2/users/42", nil, "v1-user"},
+		{"/api/v3/users", nil, "v2-users"},
+		{"/api/users", nil, "v2-users"},
+		{"/api/orders", nil, "v3-orders"},
+		{"/api/users", map[string]string{"X-API-Version": "1"}, "v1-users"},
+		{"/api/users", map[string]string{"Accept": "application/vnd.x.v1+json"}, "v1-users"},
+		{"/api/users", map[string]string{"Accept": "application/vnd.other.v1+json"}, "v2-users"},
+		{"/api/v1/orders", nil, ""},
+	}
+	for _, request := range requests {
+		hitName = ""
+		req, _ := http.NewRequest("GET", request.path, nil)
+		for header, value := range request.headers {
+			req.Header.Set(header, value)
+		}
+		versionedRouter.ServeHTTP(httptest.NewRecorder(), req)
+		if hitName != request.expected {
+			t.Error("Expected", request.path, request.headers, "to hit", request.expected, "but got", hitName)
+		}
+	}
+}
+
+func TestVersionDeprecationHeaders(t *testing.T) {
+	hitName := ""
+	versionedRouter := newVersionedRouter(&hitName)
+	req, _ := http.NewRequest("GET", "/api/v1/users", nil)
+	w := httptest.NewRecorder()
+	versionedRouter.ServeHTTP(w, req)
+	if w.Header().Get("Deprecation") != "@1546300800" {
+		t.Error("Expected a Deprecation header but got", w.Header().Get("Deprecation"))
+	}
+	if w.Header().Get("Sunset") != "Wed, 01 Jan 2020 00:00:00 GMT" {
+		t.Error("Expected a Sunset header but got", w.Header().Get("Sunset"))
+	}
+	req, _ = http.NewRequest("GET", "/api/v2/users", nil)
+	w = httptest.NewRecorder()
+	versionedRouter.ServeHTTP(w, req)
+	if w.Header().Get("Deprecation") != "" || w.Header().Get("Sunset") != "" {
+		t.Error("Expected no deprecation headers for v2")
+	}
+}
+
+func TestVersionedPrintRoutes(t *testing.T) {
+	hitName := ""
+	versionedRouter := newVersionedRouter(&hitName)
+	stdout := os.Stdout
+	reader, writer, _ := os.Pipe()
+	os.Stdout = writer
+	versionedRouter.PrintRoutes()
+	writer.Close()
+	os.Stdout = stdout
+	output, _ := ioutil.ReadAll(reader)
+	for _, expected := range []string{"/api/v1/users/:id", "[v1, deprecated, sunset 2020-01-01]", "/api/v3/orders"} {
+		if !strings.Contains(string(output), expected) {
+			t.Error("Expected the route listing to contain", expected, "but got", string(output))
+		}
+	}
+}